@@ -30,9 +30,10 @@ const (
 
 // Target names
 const (
-	Heater = "heater"
-	Cooler = "cooler"
-	Shelf  = "shelf"
+	Heater  = "heater"
+	Cooler  = "cooler"
+	Freezer = "freezer"
+	Shelf   = "shelf"
 )
 
 // Action is a json-friendly representation of an action.
@@ -43,14 +44,16 @@ type Action struct {
 	Target    string `json:"target"`    // heater, cooler or shelf. Target is the destination for move
 }
 
-type options struct {
+// Options are the harness parameters submitted with a solution.
+type Options struct {
 	Rate int64 `json:"rate"` // inverse rate in microseconds
 	Min  int64 `json:"min"`  // min pickup in microseconds
 	Max  int64 `json:"max"`  // max pickup in microseconds
 }
 
-type solution struct {
-	Options options  `json:"options"`
+// Solution is the payload submitted to solve a test problem.
+type Solution struct {
+	Options Options  `json:"options"`
 	Actions []Action `json:"actions"`
 }
 
@@ -99,8 +102,8 @@ func (c *Client) New(name string, seed int64) (string, []Order, error) {
 func (c *Client) Solve(id string, rate, min, max time.Duration, actions []Action) (string, error) {
 	url := fmt.Sprintf("%v/interview/challenge/solve?auth=%v", c.endpoint, c.auth)
 
-	payload := solution{
-		Options: options{
+	payload := Solution{
+		Options: Options{
 			Rate: rate.Microseconds(),
 			Min:  min.Microseconds(),
 			Max:  max.Microseconds(),
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ReadOrders decodes orders from r. The input may either be a JSON array of orders, as returned
+// by the problem server, or a stream of JSON objects such as a JSONL file with one order per line.
+func ReadOrders(r io.Reader) ([]Order, error) {
+	br := bufio.NewReader(r)
+
+	first, err := peekNonSpace(br)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(br)
+	if first == '[' {
+		var orders []Order
+		if err := dec.Decode(&orders); err != nil {
+			return nil, fmt.Errorf("failed to deserialize orders: %v", err)
+		}
+		return orders, nil
+	}
+
+	var orders []Order
+	for {
+		var order Order
+		err := dec.Decode(&order)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize order %d: %v", len(orders)+1, err)
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, br.UnreadByte()
+	}
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOrders(t *testing.T) {
+	expected := []Order{
+		{ID: "a1", Name: "Hot Pizza", Temp: "hot", Price: 10, Freshness: 600},
+		{ID: "b2", Name: "Cold Salad", Temp: "cold", Price: 5, Freshness: 900},
+	}
+
+	t.Run("DecodesJSONArray", func(t *testing.T) {
+		input := `[
+			{"id":"a1","name":"Hot Pizza","temp":"hot","price":10,"freshness":600},
+			{"id":"b2","name":"Cold Salad","temp":"cold","price":5,"freshness":900}
+		]`
+
+		orders, err := ReadOrders(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Equal(t, expected, orders)
+	})
+
+	t.Run("DecodesJSONL", func(t *testing.T) {
+		input := `{"id":"a1","name":"Hot Pizza","temp":"hot","price":10,"freshness":600}
+{"id":"b2","name":"Cold Salad","temp":"cold","price":5,"freshness":900}
+`
+
+		orders, err := ReadOrders(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Equal(t, expected, orders)
+	})
+
+	t.Run("ReturnsNoOrders_WhenInputIsEmpty", func(t *testing.T) {
+		orders, err := ReadOrders(strings.NewReader("  \n"))
+		require.NoError(t, err)
+		require.Empty(t, orders)
+	})
+
+	t.Run("ReturnsError_WhenLineIsMalformed", func(t *testing.T) {
+		input := `{"id":"a1","name":"Hot Pizza","temp":"hot","price":10,"freshness":600}
+{"id":"b2",
+`
+
+		_, err := ReadOrders(strings.NewReader(input))
+		require.ErrorContains(t, err, "order 2")
+	})
+}
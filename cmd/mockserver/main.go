@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"challenge/mockserver"
+	"challenge/validate"
+)
+
+var (
+	addr   = flag.String("addr", "localhost:8080", "Listen address")
+	auth   = flag.String("auth", "", "Required authentication token (any token is accepted if empty)")
+	orders = flag.Int("orders", 48, "Number of orders per problem")
+
+	coolerCapacity  = flag.Int64("cooler", 6, "Cooler capacity")
+	heaterCapacity  = flag.Int64("heater", 6, "Heater capacity")
+	freezerCapacity = flag.Int64("freezer", 0, "Freezer capacity (no freezer if zero, frozen orders go to the cooler)")
+	shelfCapacity   = flag.Int64("shelf", 12, "Shelf capacity")
+	decayFactor     = flag.Int("decay", 2, "Shelf decay multiplier")
+	tolerance       = flag.Duration("tolerance", 50*time.Millisecond, "Allowed slack on the pickup window")
+)
+
+func main() {
+	flag.Parse()
+
+	log.Printf("Serving mock challenge server on http://%v", *addr)
+	if err := http.ListenAndServe(*addr, mockserver.NewServer(*auth, *orders, validate.Config{
+		FreezerCapacity: *freezerCapacity,
+		HeaterCapacity:  *heaterCapacity,
+		CoolerCapacity:  *coolerCapacity,
+		ShelfCapacity:   *shelfCapacity,
+		Decay:           *decayFactor,
+		Tolerance:       *tolerance,
+	})); err != nil {
+		log.Fatalf("Mock server failed: %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"challenge/kitchen"
+	"challenge/server"
+)
+
+var (
+	addr = flag.String("addr", "localhost:8090", "Listen address")
+
+	coolerCapacity  = flag.Int64("cooler", 6, "Cooler capacity")
+	heaterCapacity  = flag.Int64("heater", 6, "Heater capacity")
+	freezerCapacity = flag.Int64("freezer", 0, "Freezer capacity (no freezer if zero, frozen orders go to the cooler)")
+	shelfCapacity   = flag.Int64("shelf", 12, "Shelf capacity")
+	storagesFile    = flag.String("storages", "", "Read the storage units from this JSON file instead of -heater, -cooler, -freezer, -shelf and -decay")
+
+	decayFactor   = flag.Int("decay", 2, "Shelf decay multiplier")
+	reapInterval  = flag.Duration("reap", time.Second, "Interval at which expired orders are discarded (disabled if zero)")
+	discardPolicy = flag.String("discard-policy", kitchen.DiscardOldest, "Shelf discard policy: oldest, freshness, price or value")
+	placement     = flag.String("placement", kitchen.PlacementDefault, "Placement strategy: default, freshness or no-move")
+
+	journalFile = flag.String("journal", "", "Journal every action to this file and replay it on startup")
+	logActions  = flag.Bool("log-actions", false, "Log every action the kitchen performs")
+)
+
+// eventBuffer is how many actions an event stream may fall behind before it is dropped.
+const eventBuffer = 256
+
+func main() {
+	flag.Parse()
+
+	policy, err := kitchen.DiscardPolicyByName(*discardPolicy)
+	if err != nil {
+		log.Fatalf("Invalid flags: %v", err)
+	}
+	strategy, err := kitchen.PlacementStrategyByName(*placement)
+	if err != nil {
+		log.Fatalf("Invalid flags: %v", err)
+	}
+	options := []kitchen.Option{
+		kitchen.WithDiscardPolicy(policy),
+		kitchen.WithPlacementStrategy(strategy),
+	}
+	if *logActions {
+		options = append(options, kitchen.WithLogger(slog.Default()))
+	}
+
+	units := kitchen.DefaultStorageUnits(*heaterCapacity, *coolerCapacity, *freezerCapacity, *shelfCapacity, *decayFactor)
+	if *storagesFile != "" {
+		units, err = readStorageUnits(*storagesFile)
+		if err != nil {
+			log.Fatalf("Invalid flags: %v", err)
+		}
+	}
+
+	events := kitchen.NewBroadcastSink(eventBuffer)
+	metrics := server.NewMetrics()
+	sink := kitchen.MultiSink{events, metrics}
+
+	var k *kitchen.Kitchen
+	if *journalFile != "" {
+		journal, err := kitchen.OpenJournal(*journalFile)
+		if err != nil {
+			log.Fatalf("Failed to open journal: %v", err)
+		}
+		defer journal.Close()
+
+		k, err = kitchen.NewKitchenWithJournal(journal, units, sink, kitchen.NewRealClock(), options...)
+		if err != nil {
+			log.Fatalf("Failed to create kitchen: %v", err)
+		}
+	} else {
+		k, err = kitchen.NewKitchenWithUnits(units, sink, kitchen.NewRealClock(), options...)
+		if err != nil {
+			log.Fatalf("Invalid storage units: %v", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *reapInterval > 0 {
+		go k.RunReaper(ctx, *reapInterval)
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: server.NewServer(k, events, metrics)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+
+		// Event streams only end when their subscription does
+		events.Close()
+
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	log.Printf("Serving kitchen on http://%v", *addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("Server failed: %v", err)
+	}
+
+	// Let in-flight requests finish before the journal is closed
+	<-done
+}
+
+func readStorageUnits(path string) ([]kitchen.StorageUnit, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return kitchen.ReadStorageUnits(f)
+}
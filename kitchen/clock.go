@@ -0,0 +1,60 @@
+package kitchen
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for the kitchen and its storages.
+type Clock interface {
+	Now() time.Time
+}
+
+// -- Wall clock --
+
+type RealClock struct{}
+
+func NewRealClock() RealClock {
+	return RealClock{}
+}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// -- Manually advanced clock, used by tests and simulations --
+
+type FakeClock struct {
+	now time.Time
+	mu  sync.Mutex
+}
+
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d. Negative durations are ignored.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+}
+
+// Set moves the clock to t. The clock never goes backwards.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if t.After(c.now) {
+		c.now = t
+	}
+}
@@ -0,0 +1,114 @@
+package kitchen
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// DecayModel decides how much freshness an order loses while it sits in a storage.
+type DecayModel interface {
+	// Spent returns the freshness lost after the order has been in the storage for elapsed.
+	// It must be zero for zero elapsed and never decrease as elapsed grows.
+	Spent(elapsed time.Duration) time.Duration
+}
+
+// LinearDecay loses freshness at a constant rate, Rate seconds of freshness per second.
+type LinearDecay struct {
+	Rate float64
+}
+
+func (d LinearDecay) Spent(elapsed time.Duration) time.Duration {
+	return time.Duration(float64(elapsed) * d.Rate)
+}
+
+// ExponentialDecay starts losing freshness at Rate and loses it twice as fast every Doubling,
+// for food that spoils faster and faster once it starts to go. A zero Doubling decays linearly.
+type ExponentialDecay struct {
+	Rate     float64
+	Doubling time.Duration
+}
+
+func (d ExponentialDecay) Spent(elapsed time.Duration) time.Duration {
+	if d.Doubling <= 0 {
+		return LinearDecay{Rate: d.Rate}.Spent(elapsed)
+	}
+
+	// Integral of Rate * 2^(t/Doubling) from 0 to elapsed
+	doublings := float64(elapsed) / float64(d.Doubling)
+	return time.Duration(d.Rate * float64(d.Doubling) / math.Ln2 * (math.Exp2(doublings) - 1))
+}
+
+// DecayStep is the rate of a StepDecay from After onwards.
+type DecayStep struct {
+	After time.Duration `json:"after"`
+	Rate  float64       `json:"rate"`
+}
+
+// StepDecay loses freshness at a rate that changes after fixed times in the storage. Steps must be
+// sorted by After. Orders do not decay before the first step.
+type StepDecay struct {
+	Steps []DecayStep
+}
+
+func (d StepDecay) Spent(elapsed time.Duration) time.Duration {
+	var spent float64
+	for i, step := range d.Steps {
+		if elapsed <= step.After {
+			break
+		}
+		end := elapsed
+		if i+1 < len(d.Steps) && d.Steps[i+1].After < elapsed {
+			end = d.Steps[i+1].After
+		}
+		spent += float64(end-step.After) * step.Rate
+	}
+	return time.Duration(spent)
+}
+
+// normalDecay is how orders decay unless configured otherwise.
+var normalDecay DecayModel = LinearDecay{Rate: 1}
+
+// validateDecay checks the parameters of the built-in decay models. Other models are trusted to
+// follow the contract of DecayModel.
+func validateDecay(model DecayModel) error {
+	switch m := model.(type) {
+	case nil:
+		return errors.New("no decay model")
+	case LinearDecay:
+		if m.Rate < 0 {
+			return fmt.Errorf("negative rate %v", m.Rate)
+		}
+	case ExponentialDecay:
+		if m.Rate < 0 {
+			return fmt.Errorf("negative rate %v", m.Rate)
+		}
+		if m.Doubling < 0 {
+			return fmt.Errorf("negative doubling time %s", m.Doubling)
+		}
+	case StepDecay:
+		for i, step := range m.Steps {
+			switch {
+			case step.After < 0:
+				return fmt.Errorf("step %d starts at negative time %s", i, step.After)
+			case step.Rate < 0:
+				return fmt.Errorf("step %d has negative rate %v", i, step.Rate)
+			case i > 0 && step.After < m.Steps[i-1].After:
+				return fmt.Errorf("step %d at %s comes before step %d at %s", i, step.After, i-1, m.Steps[i-1].After)
+			}
+		}
+	}
+	return nil
+}
+
+// WithDecayModel sets how orders of a temperature decay in the named storage, replacing the decay
+// of its storage unit. With the default units, orders decay at the normal rate, except hot, cold
+// and frozen orders on the shelf and frozen orders in the cooler. See DefaultStorageUnits.
+func WithDecayModel(storage string, temp Temperature, model DecayModel) Option {
+	return func(k *Kitchen) {
+		if u, ok := k.unit(storage); ok {
+			u.setDecay(temp, model)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package kitchen
+
+import (
+	"testing"
+	"time"
+
+	css "challenge/client"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecayModels(t *testing.T) {
+	t.Run("Linear", func(t *testing.T) {
+		require.Equal(t, 30*time.Second, LinearDecay{Rate: 3}.Spent(10*time.Second))
+		require.Equal(t, 5*time.Second, LinearDecay{Rate: 0.5}.Spent(10*time.Second))
+	})
+
+	t.Run("Exponential/DoublesEveryPeriod", func(t *testing.T) {
+		model := ExponentialDecay{Rate: 1, Doubling: 10 * time.Second}
+
+		// Rate * Doubling / ln 2 * (2^n - 1)
+		require.InDelta(t, 14.427, model.Spent(10*time.Second).Seconds(), 0.001)
+		require.InDelta(t, 43.281, model.Spent(20*time.Second).Seconds(), 0.001)
+		require.Zero(t, model.Spent(0))
+	})
+
+	t.Run("Exponential/LinearWithoutDoubling", func(t *testing.T) {
+		require.Equal(t, 20*time.Second, ExponentialDecay{Rate: 2}.Spent(10*time.Second))
+	})
+
+	t.Run("Step", func(t *testing.T) {
+		model := StepDecay{Steps: []DecayStep{
+			{After: 0, Rate: 1},
+			{After: 10 * time.Second, Rate: 3},
+		}}
+
+		require.Equal(t, 5*time.Second, model.Spent(5*time.Second))
+		require.Equal(t, 10*time.Second, model.Spent(10*time.Second))
+		require.Equal(t, 40*time.Second, model.Spent(20*time.Second))
+	})
+
+	t.Run("Step/NoDecayBeforeFirstStep", func(t *testing.T) {
+		model := StepDecay{Steps: []DecayStep{{After: time.Minute, Rate: 2}}}
+
+		require.Zero(t, model.Spent(30*time.Second))
+		require.Equal(t, 20*time.Second, model.Spent(70*time.Second))
+	})
+}
+
+func TestKitchen_DecayModels(t *testing.T) {
+	hot := css.Order{ID: "hot1", Name: "Hot Pizza", Temp: string(TemperatureHot), Price: 10, Freshness: 100}
+	cold := css.Order{ID: "cold1", Name: "Cold Salad", Temp: string(TemperatureCold), Price: 5, Freshness: 100}
+	room := css.Order{ID: "room1", Name: "Bread", Temp: string(TemperatureRoom), Price: 3, Freshness: 100}
+
+	freshness := func(k *Kitchen, storage string) map[string]time.Duration {
+		s, _ := k.Snapshot().Storage(storage)
+		out := make(map[string]time.Duration)
+		for _, order := range s.Orders {
+			out[order.ID] = order.Freshness
+		}
+		return out
+	}
+
+	t.Run("DefaultsMatchShelfDecay", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(0, 0, 3, 2, NewMemorySink(), clock)
+		for _, o := range []css.Order{hot, cold, room} {
+			require.NoError(t, k.PlaceOrder(o))
+		}
+
+		clock.Advance(10 * time.Second)
+		require.Equal(t, map[string]time.Duration{
+			"hot1":  80 * time.Second,
+			"cold1": 80 * time.Second,
+			"room1": 90 * time.Second,
+		}, freshness(k, css.Shelf))
+	})
+
+	t.Run("PerStorageAndTemperature", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(0, 1, 2, 1, NewMemorySink(), clock,
+			WithDecayModel(css.Shelf, TemperatureHot, LinearDecay{Rate: 2}),
+			WithDecayModel(css.Shelf, TemperatureCold, LinearDecay{Rate: 3}),
+			WithDecayModel(css.Cooler, TemperatureRoom, LinearDecay{Rate: 0.5}),
+		)
+
+		// room1 goes to the shelf, cooler is for cold orders
+		require.NoError(t, k.PlaceOrder(room))
+		require.NoError(t, k.PlaceOrder(hot))
+		clock.Advance(10 * time.Second)
+
+		shelf := freshness(k, css.Shelf)
+		require.Equal(t, 90*time.Second, shelf["room1"])
+		require.Equal(t, 80*time.Second, shelf["hot1"])
+
+		// cold1 fills the cooler, a second cold order waits on the shelf at 3x
+		require.NoError(t, k.PlaceOrder(cold))
+		_, err := k.PickUpOrder(room.ID)
+		require.NoError(t, err)
+		cold2 := cold
+		cold2.ID = "cold2"
+		require.NoError(t, k.PlaceOrder(cold2))
+		clock.Advance(10 * time.Second)
+
+		require.Equal(t, 70*time.Second, freshness(k, css.Shelf)["cold2"])
+		require.Equal(t, 90*time.Second, freshness(k, css.Cooler)["cold1"])
+	})
+
+	t.Run("LedgerAcrossModels", func(t *testing.T) {
+		step := StepDecay{Steps: []DecayStep{{After: 0, Rate: 1}, {After: 10 * time.Second, Rate: 4}}}
+
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(1, 0, 1, 1, NewMemorySink(), clock,
+			WithDecayModel(css.Shelf, TemperatureHot, step),
+		)
+		hot2 := hot
+		hot2.ID = "hot2"
+		require.NoError(t, k.PlaceOrder(hot))
+		require.NoError(t, k.PlaceOrder(hot2))
+
+		// 15s on the shelf: 10s at 1x and 5s at 4x, then hot2 moves to the heater
+		clock.Advance(15 * time.Second)
+		_, err := k.PickUpOrder(hot.ID)
+		require.NoError(t, err)
+		require.NoError(t, k.PlaceOrder(cold))
+
+		// The step model starts over in the shelf only, the heater decays at 1x
+		clock.Advance(10 * time.Second)
+		require.Equal(t, 60*time.Second, freshness(k, css.Heater)["hot2"])
+	})
+}
@@ -0,0 +1,119 @@
+package kitchen
+
+import "fmt"
+
+// DiscardPolicy decides which order to discard when the shelf is full and no order can be moved.
+type DiscardPolicy interface {
+	// Choose returns the ID of the order to discard. Items are ordered by the time they were
+	// placed on the shelf. It returns false when there is nothing to discard.
+	Choose(items []StoredOrder) (string, bool)
+}
+
+const (
+	DiscardOldest    = "oldest"
+	DiscardFreshness = "freshness"
+	DiscardPrice     = "price"
+	DiscardValue     = "value"
+)
+
+// DiscardPolicyByName returns the built-in policy registered under name.
+func DiscardPolicyByName(name string) (DiscardPolicy, error) {
+	switch name {
+	case DiscardOldest:
+		return OldestPolicy{}, nil
+	case DiscardFreshness:
+		return FreshnessPolicy{}, nil
+	case DiscardPrice:
+		return PricePolicy{}, nil
+	case DiscardValue:
+		return ValuePolicy{}, nil
+	default:
+		return nil, fmt.Errorf(
+			"unknown discard policy %q, must be one of %s, %s, %s or %s",
+			name, DiscardOldest, DiscardFreshness, DiscardPrice, DiscardValue,
+		)
+	}
+}
+
+// -- Oldest hot, cold or frozen order first, see the README discard criteria --
+
+type OldestPolicy struct{}
+
+func (OldestPolicy) Choose(items []StoredOrder) (string, bool) {
+	var cold, frozen, hot, room *StoredOrder
+	for i := range items {
+		item := &items[i]
+		switch {
+		case item.Temperature == TemperatureCold && cold == nil:
+			cold = item
+		case item.Temperature == TemperatureFrozen && frozen == nil:
+			frozen = item
+		case item.Temperature == TemperatureHot && hot == nil:
+			hot = item
+		case item.Temperature == TemperatureRoom && room == nil:
+			room = item
+		}
+	}
+
+	// The oldest of the cold, frozen and hot orders, first in that order on a tie
+	oldest := room
+	for _, item := range []*StoredOrder{cold, frozen, hot} {
+		if item != nil && (oldest == room || item.StoredAt.Before(oldest.StoredAt)) {
+			oldest = item
+		}
+	}
+
+	if oldest == nil {
+		return "", false
+	}
+	return oldest.ID, true
+}
+
+// -- Least remaining freshness first --
+
+// FreshnessPolicy ignores the order in which orders were placed and evicts the one with the least
+// remaining freshness, taking the shelf decay into account. Orders already past zero always go
+// first, the most stale of them before the others.
+type FreshnessPolicy struct{}
+
+func (FreshnessPolicy) Choose(items []StoredOrder) (string, bool) {
+	return chooseMin(items, func(item StoredOrder) float64 {
+		return float64(item.Freshness)
+	})
+}
+
+// -- Lowest price first --
+
+type PricePolicy struct{}
+
+func (PricePolicy) Choose(items []StoredOrder) (string, bool) {
+	return chooseMin(items, func(item StoredOrder) float64 {
+		return float64(item.Price)
+	})
+}
+
+// -- Lowest price times remaining freshness first --
+
+type ValuePolicy struct{}
+
+func (ValuePolicy) Choose(items []StoredOrder) (string, bool) {
+	return chooseMin(items, func(item StoredOrder) float64 {
+		return float64(item.Price) * item.Freshness.Seconds()
+	})
+}
+
+// chooseMin returns the item with the lowest score, the earliest placed one on a tie.
+func chooseMin(items []StoredOrder, score func(StoredOrder) float64) (string, bool) {
+	if len(items) == 0 {
+		return "", false
+	}
+
+	best := 0
+	bestScore := score(items[0])
+	for i := 1; i < len(items); i++ {
+		if s := score(items[i]); s < bestScore {
+			best, bestScore = i, s
+		}
+	}
+	return items[best].ID, true
+}
@@ -0,0 +1,178 @@
+package kitchen
+
+import (
+	"testing"
+	"time"
+
+	css "challenge/client"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscardPolicies(t *testing.T) {
+	now := time.Now()
+
+	// Placed in this order: cheap and fresh, expensive and stale, hot, cheap and stale
+	items := []StoredOrder{
+		{ID: "room1", Temperature: TemperatureRoom, Price: 2, StoredAt: now, Freshness: 50 * time.Second},
+		{ID: "cold1", Temperature: TemperatureCold, Price: 20, StoredAt: now.Add(time.Second), Freshness: 4 * time.Second},
+		{ID: "hot1", Temperature: TemperatureHot, Price: 9, StoredAt: now.Add(2 * time.Second), Freshness: 30 * time.Second},
+		{ID: "room2", Temperature: TemperatureRoom, Price: 2, StoredAt: now.Add(3 * time.Second), Freshness: 10 * time.Second},
+	}
+
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{DiscardOldest, "cold1"},
+		{DiscardFreshness, "cold1"},
+		{DiscardPrice, "room1"},
+		{DiscardValue, "room2"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := DiscardPolicyByName(tc.name)
+			require.NoError(t, err)
+
+			id, ok := policy.Choose(items)
+			require.True(t, ok)
+			require.Equal(t, tc.expected, id)
+
+			_, ok = policy.Choose(nil)
+			require.False(t, ok)
+		})
+	}
+
+	t.Run("Oldest/FrozenLikeHotAndCold", func(t *testing.T) {
+		items := []StoredOrder{
+			{ID: "room1", Temperature: TemperatureRoom, StoredAt: now},
+			{ID: "hot1", Temperature: TemperatureHot, StoredAt: now.Add(time.Second)},
+			{ID: "frozen1", Temperature: TemperatureFrozen, StoredAt: now.Add(time.Second)},
+			{ID: "cold1", Temperature: TemperatureCold, StoredAt: now.Add(2 * time.Second)},
+		}
+
+		// Frozen goes before hot on a tie, and any of them before room
+		id, ok := OldestPolicy{}.Choose(items)
+		require.True(t, ok)
+		require.Equal(t, "frozen1", id)
+
+		id, _ = OldestPolicy{}.Choose(items[:1])
+		require.Equal(t, "room1", id)
+	})
+
+	t.Run("UnknownPolicy", func(t *testing.T) {
+		_, err := DiscardPolicyByName("random")
+		require.ErrorContains(t, err, `unknown discard policy "random"`)
+	})
+}
+
+func TestKitchen_WithDiscardPolicy(t *testing.T) {
+	sink := NewMemorySink()
+	clock := NewFakeClock(time.Now())
+	k := NewKitchen(1, 1, 2, 2, sink, clock, WithDiscardPolicy(PricePolicy{}))
+
+	orders := []css.Order{
+		{ID: "hot1", Name: "Hot Pizza", Temp: string(TemperatureHot), Price: 10, Freshness: 600},
+		{ID: "cold1", Name: "Cold Salad", Temp: string(TemperatureCold), Price: 5, Freshness: 600},
+		{ID: "hot2", Name: "Hot Soup", Temp: string(TemperatureHot), Price: 3, Freshness: 600},
+		{ID: "cold2", Name: "Cold Drink", Temp: string(TemperatureCold), Price: 8, Freshness: 600},
+		{ID: "room1", Name: "Room Bread", Temp: string(TemperatureRoom), Price: 4, Freshness: 600},
+	}
+
+	for _, o := range orders {
+		require.NoError(t, k.PlaceOrder(o))
+		clock.Advance(time.Second)
+	}
+
+	// hot2 was discarded for room1. The oldest policy would now discard cold2, the price
+	// policy discards the cheaper room1 instead.
+	require.NoError(t, k.PlaceOrder(css.Order{
+		ID: "room2", Name: "Room Chips", Temp: string(TemperatureRoom), Price: 1, Freshness: 600,
+	}))
+
+	_, err := k.PickUpOrder("hot2")
+	require.Error(t, err)
+
+	_, err = k.PickUpOrder("room1")
+	require.Error(t, err)
+
+	_, err = k.PickUpOrder("cold2")
+	require.NoError(t, err)
+
+	_, err = k.PickUpOrder("room2")
+	require.NoError(t, err)
+}
+
+func TestKitchen_FreshnessDiscard(t *testing.T) {
+	sink := NewMemorySink()
+
+	// Both storages are kept full so everything overflows to the shelf and nothing can move
+	fillers := []css.Order{
+		{ID: "hot0", Name: "Hot Filler", Temp: string(TemperatureHot), Price: 1, Freshness: 600},
+		{ID: "cold0", Name: "Cold Filler", Temp: string(TemperatureCold), Price: 1, Freshness: 600},
+	}
+	coldOrder := css.Order{ID: "cold1", Name: "Cold Salad", Temp: string(TemperatureCold), Price: 5, Freshness: 300}
+	hotOrder := css.Order{ID: "hot1", Name: "Hot Pizza", Temp: string(TemperatureHot), Price: 5, Freshness: 3}
+	roomOrder := css.Order{ID: "room1", Name: "Room Bread", Temp: string(TemperatureRoom), Price: 5, Freshness: 20}
+	newOrder := css.Order{ID: "room2", Name: "Room Chips", Temp: string(TemperatureRoom), Price: 5, Freshness: 600}
+
+	setup := func(t *testing.T, opts ...Option) (*Kitchen, *FakeClock) {
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(1, 1, 3, 2, sink, clock, opts...)
+
+		for _, o := range append(fillers, coldOrder, hotOrder, roomOrder) {
+			require.NoError(t, k.PlaceOrder(o))
+			clock.Advance(time.Second)
+		}
+		return k, clock
+	}
+
+	t.Run("EvictsExpiredOrderFirst", func(t *testing.T) {
+		k, clock := setup(t, WithDiscardPolicy(FreshnessPolicy{}))
+
+		// hot1 has spent 2s on the shelf at twice the rate, one second past its freshness
+		clock.Advance(time.Second)
+		items := storageOf(k, css.Shelf).Items()
+		require.Equal(t, "hot1", items[1].ID)
+		require.Negative(t, items[1].Freshness)
+
+		require.NoError(t, k.PlaceOrder(newOrder))
+
+		_, err := k.PickUpOrder(hotOrder.ID)
+		require.EqualError(t, err, "order not found")
+
+		for _, id := range []string{coldOrder.ID, roomOrder.ID, newOrder.ID} {
+			_, err := k.PickUpOrder(id)
+			require.NoError(t, err, id)
+		}
+	})
+
+	t.Run("EvictsLeastFreshOrder_WhenNoneExpired", func(t *testing.T) {
+		k, _ := setup(t, WithDiscardPolicy(FreshnessPolicy{}))
+
+		// Remove the stale hot order, room1 now has the least remaining freshness
+		k.PickUpOrder(hotOrder.ID)
+		require.NoError(t, k.PlaceOrder(css.Order{
+			ID: "cold2", Name: "Cold Drink", Temp: string(TemperatureCold), Price: 5, Freshness: 600,
+		}))
+
+		require.NoError(t, k.PlaceOrder(newOrder))
+
+		_, err := k.PickUpOrder(roomOrder.ID)
+		require.EqualError(t, err, "order not found")
+
+		_, err = k.PickUpOrder(coldOrder.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("OldestPolicy_EvictsFreshOrderInsteadOfExpiredOne", func(t *testing.T) {
+		k, clock := setup(t)
+		clock.Advance(time.Second)
+
+		require.NoError(t, k.PlaceOrder(newOrder))
+
+		_, err := k.PickUpOrder(coldOrder.ID)
+		require.EqualError(t, err, "order not found")
+	})
+}
@@ -0,0 +1,39 @@
+package kitchen
+
+import (
+	"challenge/client"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrOrderNotFound is returned when picking up an order that is not in the kitchen. Status tells
+	// whether it was never placed, already picked up or discarded.
+	ErrOrderNotFound = errors.New("order not found")
+
+	// ErrDuplicateOrder is returned when placing an order whose ID is already in the kitchen.
+	ErrDuplicateOrder = errors.New("duplicate order")
+
+	// ErrKitchenFull is returned when an order cannot be stored anywhere, not even by discarding
+	// another order from the shelf.
+	ErrKitchenFull = errors.New("kitchen is full")
+
+	// ErrOrderExpired matches any ExpiredOrderError with errors.Is.
+	ErrOrderExpired = errors.New("order has expired")
+)
+
+// ExpiredOrderError is returned when an order is picked up after its freshness ran out. The
+// order has been discarded instead.
+type ExpiredOrderError struct {
+	Order     client.Order
+	Freshness time.Duration // remaining freshness at pickup, zero or negative
+}
+
+func (e *ExpiredOrderError) Error() string {
+	return fmt.Sprintf("order has expired: %v", e.Freshness)
+}
+
+func (e *ExpiredOrderError) Is(target error) bool {
+	return target == ErrOrderExpired
+}
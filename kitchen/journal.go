@@ -0,0 +1,209 @@
+package kitchen
+
+import (
+	"bufio"
+	"bytes"
+	"challenge/client"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Journal is an append-only file of every action performed by a kitchen. Each action is written
+// and synced to disk before the kitchen makes it, so a kitchen created with NewKitchenWithJournal
+// on the same file after a crash rebuilds the state it had and carries on from there.
+type Journal struct {
+	f       *os.File
+	entries []journalEntry // read when the journal is opened, replayed by the kitchen
+	seq     int64
+	mu      sync.Mutex
+}
+
+type journalEntry struct {
+	Seq       int64       `json:"seq"`
+	Timestamp int64       `json:"timestamp"` // timestamp of the action, in microseconds
+	ID        string      `json:"id"`
+	Action    string      `json:"action"`
+	Target    string      `json:"target"`
+	Order     *savedOrder `json:"order,omitempty"` // the order as stored, for places and moves
+}
+
+// OpenJournal opens the journal at path, creating it if needed, and reads the entries already in
+// it. A last entry cut short by a crash is dropped from the file, any other unreadable entry is an
+// error.
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &Journal{f: f}
+	if err := j.read(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Append writes an entry at the end of the journal and syncs it to disk.
+func (j *Journal) Append(action client.Action, order *KitchenOrder) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry := journalEntry{
+		Seq:       j.seq + 1,
+		Timestamp: action.Timestamp,
+		ID:        action.ID,
+		Action:    action.Action,
+		Target:    action.Target,
+	}
+	if order != nil {
+		saved := saveOrders([]*KitchenOrder{order})[0]
+		entry.Order = &saved
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := j.f.Sync(); err != nil {
+		return err
+	}
+
+	j.seq = entry.Seq
+	return nil
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	return j.f.Close()
+}
+
+func (j *Journal) read() error {
+	r := bufio.NewReader(j.f)
+
+	var offset int64
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A line without a newline is a write that did not complete
+			if len(line) > 0 {
+				return j.truncate(offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read journal: %w", err)
+		}
+
+		var entry journalEntry
+		if err := json.Unmarshal(bytes.TrimSpace(line), &entry); err != nil {
+			if _, err := r.Peek(1); errors.Is(err, io.EOF) {
+				return j.truncate(offset)
+			}
+			return fmt.Errorf("corrupt journal entry at offset %d: %v", offset, err)
+		}
+		if entry.Seq != j.seq+1 {
+			return fmt.Errorf("journal entry %d follows entry %d", entry.Seq, j.seq)
+		}
+
+		j.entries = append(j.entries, entry)
+		j.seq = entry.Seq
+		offset += int64(len(line))
+	}
+}
+
+func (j *Journal) truncate(offset int64) error {
+	if err := j.f.Truncate(offset); err != nil {
+		return fmt.Errorf("failed to truncate journal: %w", err)
+	}
+	return j.f.Sync()
+}
+
+// -- Replay --
+
+// replay rebuilds the state of the kitchen from the entries read when the journal was opened. It
+// is called by NewKitchenWithJournal, before the kitchen is shared.
+func (k *Kitchen) replay() error {
+	entries := k.journal.entries
+	k.journal.entries = nil
+
+	for _, entry := range entries {
+		order, err := k.replayEntry(entry)
+		if err != nil {
+			return fmt.Errorf("journal entry %d: %v", entry.Seq, err)
+		}
+		k.lastAction = entry.Timestamp
+
+		k.track(client.Action{
+			Timestamp: entry.Timestamp,
+			ID:        entry.ID,
+			Action:    entry.Action,
+			Target:    entry.Target,
+		}, order)
+	}
+	return nil
+}
+
+// replayEntry applies an entry to the storages and returns the order it concerns.
+func (k *Kitchen) replayEntry(entry journalEntry) (*KitchenOrder, error) {
+	switch entry.Action {
+	case client.Place:
+		return k.putOrder(entry.Target, entry.Order)
+	case client.Move:
+		// Moves do not record where the order came from
+		from, _, ok := k.locate(entry.ID)
+		if !ok {
+			return nil, fmt.Errorf("order %s not in the kitchen", entry.ID)
+		}
+		from.store.take(entry.ID)
+		return k.putOrder(entry.Target, entry.Order)
+	case client.Pickup, client.Discard:
+		return k.takeOrder(entry.Target, entry.ID)
+	default:
+		return nil, fmt.Errorf("unknown action %q", entry.Action)
+	}
+}
+
+// putOrder stores an order as it was recorded in the journal.
+func (k *Kitchen) putOrder(target string, saved *savedOrder) (*KitchenOrder, error) {
+	if saved == nil {
+		return nil, errors.New("missing order")
+	}
+
+	u, ok := k.unit(target)
+	if !ok {
+		return nil, fmt.Errorf("unknown target %q", target)
+	}
+	if !u.accepts(saved.Temperature) {
+		return nil, fmt.Errorf("%s order %s not accepted in the %s", saved.Temperature, saved.ID, target)
+	}
+
+	order := restoreOrder(*saved)
+	if err := u.store.put(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// takeOrder removes an order without updating its freshness.
+func (k *Kitchen) takeOrder(target string, id string) (*KitchenOrder, error) {
+	u, ok := k.unit(target)
+	if !ok {
+		return nil, fmt.Errorf("unknown target %q", target)
+	}
+
+	order, ok := u.store.take(id)
+	if !ok {
+		return nil, fmt.Errorf("order %s not in the %s", id, target)
+	}
+	return order, nil
+}
@@ -0,0 +1,186 @@
+package kitchen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	css "challenge/client"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKitchen_Journal(t *testing.T) {
+	orders := []css.Order{
+		{ID: "hot1", Name: "Hot Pizza", Temp: string(TemperatureHot), Price: 10, Freshness: 60},
+		{ID: "cold1", Name: "Cold Salad", Temp: string(TemperatureCold), Price: 5, Freshness: 90},
+		{ID: "cold2", Name: "Cold Drink", Temp: string(TemperatureCold), Price: 4, Freshness: 50},
+		{ID: "room1", Name: "Room Bread", Temp: string(TemperatureRoom), Price: 3, Freshness: 40},
+		{ID: "hot2", Name: "Hot Soup", Temp: string(TemperatureHot), Price: 8, Freshness: 70},
+		{ID: "cold3", Name: "Cold Soda", Temp: string(TemperatureCold), Price: 2, Freshness: 30},
+	}
+
+	start := time.Unix(1_700_000_000, 0).UTC()
+	units := DefaultStorageUnits(1, 1, 0, 3, 2)
+
+	// newKitchen runs orders through a journaled kitchen: a discard on the full shelf, a move to
+	// the cooler and pickups from the cooler and the shelf.
+	newKitchen := func(t *testing.T, path string) (*Kitchen, *FakeClock) {
+		journal, err := OpenJournal(path)
+		require.NoError(t, err)
+		t.Cleanup(func() { journal.Close() })
+
+		clock := NewFakeClock(start)
+		k, err := NewKitchenWithJournal(journal, units, NewMemorySink(), clock)
+		require.NoError(t, err)
+		for _, o := range orders {
+			require.NoError(t, k.PlaceOrder(o))
+			clock.Advance(time.Second)
+		}
+
+		_, err = k.PickUpOrder("cold1")
+		require.NoError(t, err)
+		require.NoError(t, k.PlaceOrder(css.Order{ID: "hot3", Name: "Hot Wings", Temp: string(TemperatureHot), Price: 6, Freshness: 80}))
+		clock.Advance(time.Second)
+		_, err = k.PickUpOrder("room1")
+		require.NoError(t, err)
+		_, err = k.PickUpOrder("hot1")
+		require.NoError(t, err)
+
+		return k, clock
+	}
+
+	t.Run("ReplayRebuildsTheState", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "journal.jsonl")
+		original, clock := newKitchen(t, path)
+
+		journal, err := OpenJournal(path)
+		require.NoError(t, err)
+		defer journal.Close()
+
+		sink := NewMemorySink()
+		restoredClock := NewFakeClock(clock.Now())
+		restored, err := NewKitchenWithJournal(journal, units, sink, restoredClock)
+		require.NoError(t, err)
+		require.Empty(t, sink.Actions())
+
+		require.Equal(t, original.Snapshot(), restored.Snapshot())
+		require.Equal(t, original.lastAction, restored.lastAction)
+		for _, id := range []string{"cold2", "cold3", "hot1", "hot3"} {
+			originalStatus, _ := original.Status(id)
+			restoredStatus, ok := restored.Status(id)
+			require.True(t, ok)
+			require.Equal(t, originalStatus, restoredStatus)
+		}
+
+		// The restored kitchen carries on where the original stopped
+		clock.Advance(10 * time.Second)
+		restoredClock.Advance(10 * time.Second)
+		require.Equal(t, original.Snapshot(), restored.Snapshot())
+
+		_, err = restored.PickUpOrder("hot2")
+		require.NoError(t, err)
+		require.Len(t, sink.Actions(), 1)
+		require.Greater(t, sink.Actions()[0].Timestamp, original.lastAction)
+	})
+
+	t.Run("AppendsAfterTheLastEntry", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "journal.jsonl")
+		_, clock := newKitchen(t, path)
+
+		journal, err := OpenJournal(path)
+		require.NoError(t, err)
+		count := journal.seq
+
+		k, err := NewKitchenWithJournal(journal, units, NewMemorySink(), NewFakeClock(clock.Now()))
+		require.NoError(t, err)
+		_, err = k.PickUpOrder("hot2")
+		require.NoError(t, err)
+		require.NoError(t, journal.Close())
+
+		journal, err = OpenJournal(path)
+		require.NoError(t, err)
+		defer journal.Close()
+		require.Len(t, journal.entries, int(count)+1)
+		require.Equal(t, count+1, journal.seq)
+	})
+
+	t.Run("DropsTornLastEntry", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "journal.jsonl")
+		original, _ := newKitchen(t, path)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		require.NoError(t, err)
+		_, err = f.WriteString(`{"seq":99,"timestamp":`)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		journal, err := OpenJournal(path)
+		require.NoError(t, err)
+		defer journal.Close()
+
+		truncated, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, data, truncated)
+
+		restored, err := NewKitchenWithJournal(journal, units, NewMemorySink(), original.clock)
+		require.NoError(t, err)
+		require.Equal(t, original.Snapshot(), restored.Snapshot())
+	})
+
+	t.Run("RejectsCorruptEntry", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "journal.jsonl")
+		newKitchen(t, path)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		lines := strings.SplitAfter(string(data), "\n")
+		lines[1] = "not json\n"
+		require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "")), 0o644))
+
+		_, err = OpenJournal(path)
+		require.ErrorContains(t, err, "corrupt journal entry")
+	})
+
+	t.Run("FailsWhenReplayFails", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "journal.jsonl")
+		newKitchen(t, path)
+
+		journal, err := OpenJournal(path)
+		require.NoError(t, err)
+		defer journal.Close()
+
+		// The journal holds more shelf orders than this kitchen can store
+		_, err = NewKitchenWithJournal(journal, DefaultStorageUnits(1, 1, 0, 1, 2), NewMemorySink(), NewFakeClock(start))
+		require.ErrorContains(t, err, "failed to replay journal")
+		require.ErrorContains(t, err, "more orders than capacity 1")
+	})
+
+	t.Run("MakesNoChangeWhenWriteFails", func(t *testing.T) {
+		journal, err := OpenJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+		require.NoError(t, err)
+
+		sink := NewMemorySink()
+		k, err := NewKitchenWithJournal(journal, units, sink, NewFakeClock(start))
+		require.NoError(t, err)
+		require.NoError(t, k.PlaceOrder(orders[0]))
+		require.NoError(t, journal.Close())
+		before := k.Snapshot()
+
+		err = k.PlaceOrder(orders[1])
+		require.ErrorIs(t, err, os.ErrClosed)
+		require.Len(t, sink.Actions(), 1)
+
+		_, err = k.PickUpOrder("hot1")
+		require.ErrorIs(t, err, os.ErrClosed)
+		require.Equal(t, 1, len(sink.Actions()))
+
+		// Nothing the journal missed was done
+		require.Equal(t, before, k.Snapshot())
+		require.Equal(t, err, k.JournalErr())
+	})
+}
@@ -2,7 +2,6 @@ package kitchen
 
 import (
 	"challenge/client"
-	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -10,26 +9,115 @@ import (
 )
 
 type Kitchen struct {
-	heater *Storage
-	cooler *Storage
-	shelf  *ShelfStorage
-	logger *slog.Logger
-	mu     sync.Mutex
+	units         []*unit // in priority order
+	sink          ActionSink
+	logger        *slog.Logger
+	clock         Clock
+	discardPolicy DiscardPolicy
+	placement     PlacementStrategy
+	lastAction    int64 // timestamp of the last action, in microseconds
+	journal       *Journal
+	journalErr    error                   // first journal failure, after which the kitchen refuses new work
+	orders        map[string]*OrderStatus // lifecycle of stored and recently finished orders
+	finished      []*OrderStatus          // finished orders, oldest first
+	historyLimit  int
+	mu            sync.Mutex
 }
 
+// Option customizes a Kitchen created by NewKitchen.
+type Option func(*Kitchen)
+
+// WithDiscardPolicy sets the policy consulted when an order has to be discarded from an overflow
+// storage, the shelf in a kitchen created with NewKitchen.
+// The default is OldestPolicy.
+func WithDiscardPolicy(policy DiscardPolicy) Option {
+	return func(k *Kitchen) {
+		k.discardPolicy = policy
+	}
+}
+
+// WithLogger logs every action the kitchen performs. Actions are not logged by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(k *Kitchen) {
+		k.logger = logger
+	}
+}
+
+// WithPlacementStrategy sets the strategy deciding where incoming orders go and which orders
+// to move to make room. The default is DefaultPlacement.
+func WithPlacementStrategy(strategy PlacementStrategy) Option {
+	return func(k *Kitchen) {
+		k.placement = strategy
+	}
+}
+
+// NewKitchen creates a kitchen with a heater for hot orders, a cooler for cold orders and a shelf
+// for any order, where hot and cold orders decay decay times faster. It has no freezer, frozen
+// orders go to the cooler. See DefaultStorageUnits.
 func NewKitchen(
 	hotCapacity int64,
 	coldCapacity int64,
 	shelfCapacity int64,
 	decay int,
-	logger *slog.Logger,
+	sink ActionSink,
+	clock Clock,
+	opts ...Option,
 ) *Kitchen {
-	return &Kitchen{
-		heater: NewStorage(hotCapacity),
-		cooler: NewStorage(coldCapacity),
-		shelf:  NewShelfStorage(shelfCapacity, decay),
-		logger: logger,
+	return newKitchen(DefaultStorageUnits(hotCapacity, coldCapacity, 0, shelfCapacity, decay), sink, clock, opts...)
+}
+
+// NewKitchenWithUnits creates a kitchen made of the given storage units. It returns an error if
+// a unit has no name or temperature, an unknown temperature, a negative capacity, an invalid
+// decay model, or the name of another unit.
+func NewKitchenWithUnits(units []StorageUnit, sink ActionSink, clock Clock, opts ...Option) (*Kitchen, error) {
+	if err := validateUnits(units); err != nil {
+		return nil, err
 	}
+	return newKitchen(units, sink, clock, opts...), nil
+}
+
+func newKitchen(units []StorageUnit, sink ActionSink, clock Clock, opts ...Option) *Kitchen {
+	k := &Kitchen{
+		units:         newUnits(units, clock),
+		sink:          sink,
+		logger:        slog.New(slog.DiscardHandler),
+		clock:         clock,
+		discardPolicy: OldestPolicy{},
+		placement:     DefaultPlacement{},
+		orders:        make(map[string]*OrderStatus),
+		historyLimit:  defaultHistoryLimit,
+	}
+
+	for _, opt := range opts {
+		opt(k)
+	}
+
+	return k
+}
+
+// NewKitchenWithJournal creates a kitchen made of the given storage units that writes every action
+// to the journal before making it. It first replays the actions already in the journal to rebuild
+// the state they describe, so the units must be those of the kitchen that wrote them. It returns
+// an error if the units are invalid or the journal cannot be replayed on them.
+func NewKitchenWithJournal(journal *Journal, units []StorageUnit, sink ActionSink, clock Clock, opts ...Option) (*Kitchen, error) {
+	k, err := NewKitchenWithUnits(units, sink, clock, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	k.journal = journal
+	if err := k.replay(); err != nil {
+		return nil, fmt.Errorf("failed to replay journal: %w", err)
+	}
+	return k, nil
+}
+
+// JournalErr returns the error that stopped the kitchen from writing its journal, if any. Once
+// set, PlaceOrder and PickUpOrder return it without doing anything.
+func (k *Kitchen) JournalErr() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.journalErr
 }
 
 func (k *Kitchen) PlaceOrder(newOrder client.Order) error {
@@ -44,129 +132,217 @@ func (k *Kitchen) PlaceOrder(newOrder client.Order) error {
 		Temperature: Temperature(newOrder.Temp),
 		Price:       newOrder.Price,
 		Freshness:   time.Duration(newOrder.Freshness) * time.Second,
-		cookedAt:    time.Now(),
 	}
 
-	var placed bool
-	var storageName string
-	switch order.Temperature {
-	case TemperatureHot:
-		placed = k.heater.Add(order)
-		storageName = client.Heater
-	case TemperatureCold:
-		placed = k.cooler.Add(order)
-		storageName = client.Cooler
-	default:
-		placed = k.shelf.Add(order)
-		storageName = client.Shelf
-	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
 
-	if !placed {
-		placed = k.placeInShelf(order)
-		storageName = client.Shelf
+	if k.journalErr != nil {
+		return k.journalErr
 	}
-
-	// Log pickup and return results
-	if !placed {
-		return errors.New("unable to place order")
+	// A retried order is accepted again as long as it is the same order
+	if status, ok := k.orders[order.ID]; ok && !status.Finished() {
+		if status.Order == newOrder {
+			return nil
+		}
+		return fmt.Errorf("%w %s", ErrDuplicateOrder, order.ID)
 	}
 
-	k.logger.Info(client.Place, "order id", order.ID, "target", storageName)
-	return nil
+	placement := k.placement.Place(*order, k.placementView())
+	return k.place(order, placement)
 }
 
 func (k *Kitchen) PickUpOrder(orderID string) (client.Order, error) {
-	var foundOrder *KitchenOrder
+	picked, err := k.PickUp(orderID)
+	return picked.Order, err
+}
 
-	// Try to find and remove the order from  any of the three storages
-	var storageName string
+// PickedUpOrder is an order handed over by PickUp, with its exact remaining freshness.
+type PickedUpOrder struct {
+	Order     client.Order
+	Freshness time.Duration
+}
 
-	if order, ok := k.heater.Remove(orderID); ok {
-		foundOrder = order
-		storageName = client.Heater
-	} else if order, ok := k.cooler.Remove(orderID); ok {
-		foundOrder = order
-		storageName = client.Cooler
-	} else if order, ok := k.shelf.Remove(orderID); ok {
-		storageName = client.Shelf
-		foundOrder = order
-	}
+// PickUp picks up an order like PickUpOrder, keeping the remaining freshness the order's whole
+// seconds leave out.
+func (k *Kitchen) PickUp(orderID string) (PickedUpOrder, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
 
-	if foundOrder == nil {
-		return client.Order{}, errors.New("order not found")
+	if k.journalErr != nil {
+		return PickedUpOrder{}, k.journalErr
 	}
 
-	k.logger.Info(client.Pickup, "order id", foundOrder.ID, "target", storageName)
-
-	if foundOrder.Freshness <= 0 {
-		// Should this also be logged as discarded?
-		return client.Order{}, fmt.Errorf("order has expired: %+v", foundOrder.Freshness)
+	// Find the order in any of the storages
+	from, foundOrder, ok := k.locate(orderID)
+	if !ok {
+		return PickedUpOrder{}, ErrOrderNotFound
 	}
 
-	return client.Order{
+	at := k.now()
+	remaining := foundOrder.getFreshness(at, from.store.decayOf(foundOrder))
+
+	order := client.Order{
 		ID:        foundOrder.ID,
 		Name:      foundOrder.Name,
 		Temp:      string(foundOrder.Temperature),
 		Price:     foundOrder.Price,
-		Freshness: int(foundOrder.Freshness),
-	}, nil
+		Freshness: int(remaining / time.Second),
+	}
+
+	// Spoiled food is thrown away rather than handed over
+	if remaining <= 0 {
+		if err := k.remove(client.Discard, foundOrder, from, at); err != nil {
+			return PickedUpOrder{}, err
+		}
+		return PickedUpOrder{}, &ExpiredOrderError{Order: order, Freshness: remaining}
+	}
+
+	if err := k.remove(client.Pickup, foundOrder, from, at); err != nil {
+		return PickedUpOrder{}, err
+	}
+	return PickedUpOrder{Order: order, Freshness: remaining}, nil
 }
 
 // -- Helper Functions --
 
-func (k *Kitchen) placeInShelf(order *KitchenOrder) bool {
-	k.mu.Lock()
-	defer k.mu.Unlock()
+// now returns the time of the next action, which is never before the previous one. The state
+// change of an action is made at that time and the action is published with it, so the ledger
+// and the storages agree.
+func (k *Kitchen) now() time.Time {
+	now := k.clock.Now()
+	if last := time.UnixMicro(k.lastAction); now.Before(last) {
+		return last
+	}
+	return now
+}
+
+// perform carries out an action at the given time: it journals the action, makes its state change
+// with change, then publishes it to the sink and logs it. stored is the order as the action leaves
+// it in its target, journaled for places and moves. It must be called with k.mu held, once the
+// action is known to succeed. An action that cannot be journaled is neither made nor published,
+// and the kitchen refuses new work from then on.
+func (k *Kitchen) perform(action string, order, stored *KitchenOrder, target string, at time.Time, change func()) error {
+	if k.journalErr != nil {
+		return k.journalErr
+	}
 
-	if k.shelf.HasSpace() {
-		return k.shelf.Add(order)
+	a := client.Action{
+		Timestamp: at.UnixMicro(),
+		ID:        order.ID,
+		Action:    action,
+		Target:    target,
 	}
 
-	if order.Temperature == TemperatureCold && k.moveShelfHotOrder() {
-		k.moveShelfColdOrder()
-	} else if order.Temperature == TemperatureHot && k.moveShelfColdOrder() {
-		k.moveShelfHotOrder()
-	} else {
-		toDiscard := k.shelf.GetOrderToDiscard()
-		k.shelf.Remove(toDiscard.ID)
-		k.logger.Info(client.Discard, "order id", toDiscard.ID, "target", client.Shelf)
+	if k.journal != nil {
+		if err := k.journal.Append(a, stored); err != nil {
+			k.journalErr = fmt.Errorf("failed to write journal: %w", err)
+			k.logger.Error("failed to write journal", "error", err)
+			return k.journalErr
+		}
 	}
 
-	return k.shelf.Add(order)
+	change()
+	k.lastAction = a.Timestamp
+
+	k.track(a, order)
+	k.sink.Emit(a)
+	k.logger.Info(action, "order id", order.ID, "target", target)
+	return nil
 }
 
-func (k *Kitchen) moveShelfColdOrder() bool {
-	if !k.cooler.HasSpace() {
-		return false
+func (k *Kitchen) placementView() PlacementView {
+	now := k.clock.Now()
+
+	view := PlacementView{Storages: make([]StorageView, len(k.units))}
+	for i, u := range k.units {
+		view.Storages[i] = StorageView{
+			Name:         u.Name,
+			Temperatures: u.Temperatures,
+			StorageState: u.store.State(),
+			Items:        u.store.itemsAt(now),
+		}
 	}
+	return view
+}
 
-	order := k.shelf.GetFirstColdOrder()
-	if order == nil || !k.cooler.Add(order) {
-		return false
+// place carries out a placement, falling back to the overflow storage of the order when the
+// target cannot take it.
+func (k *Kitchen) place(order *KitchenOrder, placement Placement) error {
+	overflow, ok := k.overflow(order.Temperature)
+	if !ok {
+		return ErrKitchenFull
 	}
 
-	if _, ok := k.shelf.Remove(order.ID); !ok {
-		return false
+	if target, ok := k.unit(placement.Target); ok && target != overflow && target.accepts(order.Temperature) && target.store.HasSpace() {
+		return k.placeIn(order, target)
 	}
 
-	k.logger.Info(client.Move, "order id", order.ID, "target", client.Cooler)
-	return true
+	return k.placeInOverflow(order, overflow, placement.Moves)
 }
 
-func (k *Kitchen) moveShelfHotOrder() bool {
-	if !k.heater.HasSpace() {
-		return false
+func (k *Kitchen) placeInOverflow(order *KitchenOrder, overflow *unit, moves []Move) error {
+	for _, move := range moves {
+		if err := k.move(move); err != nil {
+			return err
+		}
+	}
+
+	if !overflow.store.HasSpace() {
+		if id, ok := k.discardPolicy.Choose(overflow.store.Items()); ok {
+			if discarded, ok := overflow.store.Get(id); ok {
+				if err := k.remove(client.Discard, discarded, overflow, k.now()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if !overflow.store.HasSpace() {
+		return ErrKitchenFull
 	}
+	return k.placeIn(order, overflow)
+}
+
+// placeIn places an order in a storage with space for it.
+func (k *Kitchen) placeIn(order *KitchenOrder, to *unit) error {
+	at := k.now()
+	placed := *order
+	placed.store(at)
+
+	return k.perform(client.Place, order, &placed, to.Name, at, func() {
+		to.store.addAt(order, at)
+	})
+}
 
-	order := k.shelf.GetFirstHotOrder()
-	if order == nil || !k.heater.Add(order) {
-		return false
+// move moves an order to another storage accepting its temperature if it has space. A move that
+// cannot be made is skipped.
+func (k *Kitchen) move(move Move) error {
+	from, order, ok := k.locate(move.ID)
+	if !ok {
+		return nil
 	}
 
-	if _, ok := k.shelf.Remove(order.ID); !ok {
-		return false
+	to, ok := k.unit(move.Target)
+	if !ok || to == from || !to.accepts(order.Temperature) || !to.store.HasSpace() {
+		return nil
 	}
 
-	k.logger.Info(client.Move, "order id", order.ID, "target", client.Heater)
-	return true
+	// Leave the storage first, so the time spent there is settled at its decay rate
+	at := k.now()
+	moved := *order
+	moved.settle(at, from.store.decayOf(order))
+	moved.store(at)
+
+	return k.perform(client.Move, order, &moved, to.Name, at, func() {
+		from.store.removeAt(order.ID, at)
+		to.store.addAt(order, at)
+	})
+}
+
+// remove takes an order out of its storage, for a pickup or a discard.
+func (k *Kitchen) remove(action string, order *KitchenOrder, from *unit, at time.Time) error {
+	return k.perform(action, order, nil, from.Name, at, func() {
+		from.store.removeAt(order.ID, at)
+	})
 }
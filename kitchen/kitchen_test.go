@@ -2,8 +2,9 @@ package kitchen
 
 import (
 	css "challenge/client"
-	"log/slog"
-	"os"
+	"challenge/validate"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -18,6 +19,20 @@ func assertOrderMatch(t *testing.T, expected css.Order, actual css.Order) {
 	require.Equal(t, expected.Price, actual.Price, "Price mismatch")
 }
 
+// Helper function to compare actions regardless of when they happened
+func withoutTimestamps(actions []css.Action) []css.Action {
+	for i := range actions {
+		actions[i].Timestamp = 0
+	}
+	return actions
+}
+
+// Helper function to reach the storage behind a unit of a kitchen
+func storageOf(k *Kitchen, name string) storage {
+	u, _ := k.unit(name)
+	return u.store
+}
+
 func TestKitchen_PlaceOrder_PickUpOrder(t *testing.T) {
 	hotOrder := css.Order{
 		ID:        "hot1",
@@ -84,13 +99,12 @@ func TestKitchen_PlaceOrder_PickUpOrder(t *testing.T) {
 	}
 
 	decay := 2
-	f, _ := os.OpenFile("myorders_v3", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	logger := slog.New(slog.NewTextHandler(f, nil))
+	sink := NewMemorySink()
 
 	const one int64 = 1
 
 	t.Run("PlaceOrder/RoutesOrdersToPreferredStorage_WhenCapacityAvailable", func(t *testing.T) {
-		k := NewKitchen(one, one, one, decay, logger)
+		k := NewKitchen(one, one, one, decay, sink, NewFakeClock(time.Now()))
 
 		k.PlaceOrder(coldOrder)
 		k.PlaceOrder(hotOrder)
@@ -101,51 +115,52 @@ func TestKitchen_PlaceOrder_PickUpOrder(t *testing.T) {
 		require.True(t, ok, "Error should be of type ValidationErrors")
 		require.Len(t, vErrs, 1)
 		require.Equal(t, "1 validation errors occurred", err.Error())
-		require.Equal(t, "must be one of hot, cold, or room", vErrs[0].Message)
+		require.Equal(t, "must be one of hot, cold, room, or frozen", vErrs[0].Message)
 		require.Equal(t, "Temp", vErrs[0].Field)
 
-		require.Equal(t, one, k.heater.Len())
-		require.Equal(t, one, k.cooler.Len())
-		require.Equal(t, one, k.shelf.Len())
+		require.Equal(t, one, storageOf(k, css.Heater).Len())
+		require.Equal(t, one, storageOf(k, css.Cooler).Len())
+		require.Equal(t, one, storageOf(k, css.Shelf).Len())
 
 		// Verify cold order was stored
 		pickColdOrder, err := k.PickUpOrder(coldOrder.ID)
 		require.Nil(t, err)
 		assertOrderMatch(t, coldOrder, pickColdOrder)
-		require.Zero(t, k.cooler.Len())
+		require.Zero(t, storageOf(k, css.Cooler).Len())
 
 		// Verify hot order was stored
 		pickupHotOrder, err := k.PickUpOrder(hotOrder.ID)
 		require.Nil(t, err)
 		assertOrderMatch(t, hotOrder, pickupHotOrder)
-		require.Zero(t, k.heater.Len())
+		require.Zero(t, storageOf(k, css.Heater).Len())
 
 		// Verify room order was stored
 		pickupRoomOrder, err := k.PickUpOrder(roomOrder.ID)
 		require.Nil(t, err)
 		assertOrderMatch(t, roomOrder, pickupRoomOrder)
-		require.Zero(t, k.shelf.Len())
+		require.Zero(t, storageOf(k, css.Shelf).Len())
 
 		// Verify invalidOrder was not stored
 		pickupInvalidOrder, err := k.PickUpOrder(invalidOrder.ID)
 		require.Error(t, err)
 		require.Zero(t, pickupInvalidOrder)
+		require.ErrorIs(t, err, ErrOrderNotFound)
 		require.Equal(t, "order not found", err.Error())
 	})
 
 	t.Run(
 		"PlaceOrder/Shelf_DiscardPolicy_DiscardsShelfOrder_WhenAllStoragesAreFull",
 		func(t *testing.T) {
-			k := NewKitchen(one, one, one, decay, logger)
+			k := NewKitchen(one, one, one, decay, sink, NewFakeClock(time.Now()))
 
 			k.PlaceOrder(coldOrder)
 			k.PlaceOrder(hotOrder)
 			k.PlaceOrder(roomOrder)
 			k.PlaceOrder(coldOrder4)
 
-			require.Equal(t, one, k.heater.Len())
-			require.Equal(t, one, k.cooler.Len())
-			require.Equal(t, one, k.shelf.Len())
+			require.Equal(t, one, storageOf(k, css.Heater).Len())
+			require.Equal(t, one, storageOf(k, css.Cooler).Len())
+			require.Equal(t, one, storageOf(k, css.Shelf).Len())
 
 			pickColdOrder, err := k.PickUpOrder(coldOrder.ID)
 			require.Nil(t, err)
@@ -165,7 +180,7 @@ func TestKitchen_PlaceOrder_PickUpOrder(t *testing.T) {
 		})
 
 	t.Run("PlaceOrder/MovesHotOrderFromShelfToHeater_WhenHeaterHasCapacity", func(t *testing.T) {
-		k := NewKitchen(one, one, 2, decay, logger)
+		k := NewKitchen(one, one, 2, decay, sink, NewFakeClock(time.Now()))
 
 		k.PlaceOrder(hotOrder)
 		k.PlaceOrder(roomOrder)
@@ -184,7 +199,7 @@ func TestKitchen_PlaceOrder_PickUpOrder(t *testing.T) {
 	})
 
 	t.Run("PlaceOrder/MovesColdOrderFromShelfToCooler_WhenCoolerHasCapacity", func(t *testing.T) {
-		k := NewKitchen(one, one, 2, decay, logger)
+		k := NewKitchen(one, one, 2, decay, sink, NewFakeClock(time.Now()))
 
 		k.PlaceOrder(hotOrder)
 		k.PlaceOrder(roomOrder)
@@ -207,7 +222,7 @@ func TestKitchen_PlaceOrder_PickUpOrder(t *testing.T) {
 	})
 
 	t.Run("PlaceOrder/Shelf_DiscardPolicy_DiscardsRoomOrder_ToPlaceColdOrder", func(t *testing.T) {
-		k := NewKitchen(one, one, one, decay, logger)
+		k := NewKitchen(one, one, one, decay, sink, NewFakeClock(time.Now()))
 
 		k.PlaceOrder(roomOrder)
 		k.PlaceOrder(coldOrder)
@@ -227,7 +242,7 @@ func TestKitchen_PlaceOrder_PickUpOrder(t *testing.T) {
 	})
 
 	t.Run("PlaceOrder/Shelf_DiscardPolicy_DiscardsRoomOrder_ToPlaceHotOrder", func(t *testing.T) {
-		k := NewKitchen(one, one, one, decay, logger)
+		k := NewKitchen(one, one, one, decay, sink, NewFakeClock(time.Now()))
 
 		k.PlaceOrder(roomOrder)
 		k.PlaceOrder(hotOrder)
@@ -247,7 +262,7 @@ func TestKitchen_PlaceOrder_PickUpOrder(t *testing.T) {
 	})
 
 	t.Run("PlaceOrder/DoesNotMoveColdOrderFromShelf_WhenCoolerIsFull", func(t *testing.T) {
-		k := NewKitchen(one, one, 2, decay, logger)
+		k := NewKitchen(one, one, 2, decay, sink, NewFakeClock(time.Now()))
 
 		k.PlaceOrder(hotOrder)
 		k.PlaceOrder(roomOrder)
@@ -265,60 +280,87 @@ func TestKitchen_PlaceOrder_PickUpOrder(t *testing.T) {
 	})
 
 	t.Run("PickUpOrder/Fails_WhenOrderExpiredInPreferredStorage", func(t *testing.T) {
-		k := NewKitchen(one, one, one, decay, logger)
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(one, one, one, decay, sink, clock)
 		k.PlaceOrder(coldOrder2)
-		require.Equal(t, one, k.cooler.Len())
+		require.Equal(t, one, storageOf(k, css.Cooler).Len())
 
-		time.Sleep(1 * time.Second)
+		clock.Advance(1 * time.Second)
 		order, err := k.PickUpOrder(coldOrder2.ID)
 
 		require.Error(t, err)
 		require.Zero(t, order)
-		require.Zero(t, k.cooler.Len())
+		require.Zero(t, storageOf(k, css.Cooler).Len())
 	})
 
 	t.Run("PickUpOrder/Fails_WhenOrderExpiredInSecondaryStorage", func(t *testing.T) {
-		k := NewKitchen(one, one, one, decay, logger)
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(one, one, one, decay, sink, clock)
 		k.PlaceOrder(coldOrder2)
-		require.Equal(t, one, k.cooler.Len())
+		require.Equal(t, one, storageOf(k, css.Cooler).Len())
 
-		time.Sleep(1 * time.Second)
+		clock.Advance(1 * time.Second)
 		order, err := k.PickUpOrder(coldOrder2.ID)
 
 		require.Error(t, err)
 		require.Zero(t, order)
-		require.Zero(t, k.cooler.Len())
+		require.Zero(t, storageOf(k, css.Cooler).Len())
 	})
 
 	t.Run("PickUpOrder/Fails_WhenColdOrderExpiresOnShelf", func(t *testing.T) {
-		k := NewKitchen(one, one, one, decay, logger)
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(one, one, one, decay, sink, clock)
 		k.PlaceOrder(coldOrder2)
 		k.PlaceOrder(coldOrder3)
 
-		require.Equal(t, one, k.cooler.Len())
-		require.Equal(t, one, k.shelf.Len())
+		require.Equal(t, one, storageOf(k, css.Cooler).Len())
+		require.Equal(t, one, storageOf(k, css.Shelf).Len())
 
-		time.Sleep(2 * time.Second)
+		clock.Advance(2 * time.Second)
 		order, err := k.PickUpOrder(coldOrder3.ID)
 
-		require.Equal(t, one, k.cooler.Len())
-		require.Zero(t, k.shelf.Len())
+		require.Equal(t, one, storageOf(k, css.Cooler).Len())
+		require.Zero(t, storageOf(k, css.Shelf).Len())
 
 		require.Error(t, err)
 		require.Zero(t, order)
 	})
 
+	t.Run("PickUpOrder/LogsDiscard_WhenOrderExpired", func(t *testing.T) {
+		sink := NewMemorySink()
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(one, one, one, decay, sink, clock)
+		k.PlaceOrder(coldOrder4)
+
+		clock.Advance(31 * time.Second)
+		order, err := k.PickUpOrder(coldOrder4.ID)
+
+		require.Zero(t, order)
+		require.ErrorIs(t, err, ErrOrderExpired)
+		require.EqualError(t, err, "order has expired: -1s")
+
+		var expiredErr *ExpiredOrderError
+		require.ErrorAs(t, err, &expiredErr)
+		require.Equal(t, -time.Second, expiredErr.Freshness)
+		assertOrderMatch(t, coldOrder4, expiredErr.Order)
+
+		require.Equal(t, []css.Action{
+			{ID: "cold4", Action: css.Place, Target: css.Cooler},
+			{ID: "cold4", Action: css.Discard, Target: css.Cooler},
+		}, withoutTimestamps(sink.Actions()))
+	})
+
 	t.Run("PlaceOrder/ReturnsValidationError_WhenOrderIsInvalid", func(t *testing.T) {
-		k := NewKitchen(one, one, one, decay, logger)
+		k := NewKitchen(one, one, one, decay, sink, NewFakeClock(time.Now()))
 		invalidOrder := css.Order{}
 
 		err := k.PlaceOrder(invalidOrder)
 
 		require.Error(t, err)
 
-		require.Zero(t, k.heater.Len())
-		require.Zero(t, k.cooler.Len())
-		require.Zero(t, k.shelf.Len())
+		require.Zero(t, storageOf(k, css.Heater).Len())
+		require.Zero(t, storageOf(k, css.Cooler).Len())
+		require.Zero(t, storageOf(k, css.Shelf).Len())
 
 		vErrs, ok := err.(ValidationErrors)
 		require.True(t, ok, "Error should be of type ValidationErrors")
@@ -331,7 +373,7 @@ func TestKitchen_PlaceOrder_PickUpOrder(t *testing.T) {
 		require.Contains(t, vErrs[1].Message, "is required")
 
 		require.Equal(t, "Temp", vErrs[2].Field)
-		require.Contains(t, vErrs[2].Message, "must be one of hot, cold, or room")
+		require.Contains(t, vErrs[2].Message, "must be one of hot, cold, room, or frozen")
 
 		require.Equal(t, "Price", vErrs[3].Field)
 		require.Equal(t, "must be greater than zero", vErrs[3].Message)
@@ -341,4 +383,270 @@ func TestKitchen_PlaceOrder_PickUpOrder(t *testing.T) {
 
 		require.ErrorContains(t, err, "5 validation errors occurred")
 	})
+
+	t.Run("PlaceOrder/AcceptsRetriedOrder_WhenPayloadIsIdentical", func(t *testing.T) {
+		sink := NewMemorySink()
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(one, one, one, decay, sink, clock)
+		require.NoError(t, k.PlaceOrder(hotOrder))
+		require.NoError(t, k.PlaceOrder(hotOrder2))
+
+		// hot2 is on the shelf, a retry neither stores it twice nor goes to the heater
+		clock.Advance(time.Second)
+		require.NoError(t, k.PlaceOrder(hotOrder))
+		require.NoError(t, k.PlaceOrder(hotOrder2))
+		require.Equal(t, one, storageOf(k, css.Heater).Len())
+		require.Equal(t, one, storageOf(k, css.Shelf).Len())
+		require.Len(t, sink.Actions(), 2)
+
+		_, err := k.PickUpOrder(hotOrder2.ID)
+		require.NoError(t, err)
+		require.Zero(t, storageOf(k, css.Shelf).Len())
+		require.True(t, storageOf(k, css.Shelf).HasSpace())
+	})
+
+	t.Run("PlaceOrder/ReturnsErrDuplicateOrder_WhenOrderIsStored", func(t *testing.T) {
+		sink := NewMemorySink()
+		k := NewKitchen(one, one, one, decay, sink, NewFakeClock(time.Now()))
+		require.NoError(t, k.PlaceOrder(hotOrder))
+
+		changed := hotOrder
+		changed.Price++
+		err := k.PlaceOrder(changed)
+		require.ErrorIs(t, err, ErrDuplicateOrder)
+		require.EqualError(t, err, "duplicate order hot1")
+		require.Equal(t, one, storageOf(k, css.Heater).Len())
+		require.Zero(t, storageOf(k, css.Shelf).Len())
+		require.Len(t, sink.Actions(), 1)
+
+		// Once picked up, the ID can be used again
+		_, err = k.PickUpOrder(hotOrder.ID)
+		require.NoError(t, err)
+		require.NoError(t, k.PlaceOrder(hotOrder))
+	})
+
+	t.Run("PlaceOrder/ReturnsErrKitchenFull_WhenNoStorageCanTakeTheOrder", func(t *testing.T) {
+		k := NewKitchen(0, 0, 0, decay, NewMemorySink(), NewFakeClock(time.Now()))
+
+		err := k.PlaceOrder(hotOrder)
+		require.ErrorIs(t, err, ErrKitchenFull)
+	})
+
+	t.Run("PickUp/ReturnsExactFreshness_WhenOrderIsPickedUp", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(one, one, one, decay, NewMemorySink(), clock)
+		require.NoError(t, k.PlaceOrder(coldOrder4))
+
+		clock.Advance(10*time.Second + 250*time.Millisecond)
+		picked, err := k.PickUp(coldOrder4.ID)
+
+		require.NoError(t, err)
+		require.Equal(t, 19*time.Second+750*time.Millisecond, picked.Freshness)
+		require.Equal(t, 19, picked.Order.Freshness)
+		assertOrderMatch(t, coldOrder4, picked.Order)
+	})
+
+	t.Run("PickUpOrder/ReturnsErrOrderNotFound_WhenOrderWasAlreadyPickedUp", func(t *testing.T) {
+		k := NewKitchen(one, one, one, decay, NewMemorySink(), NewFakeClock(time.Now()))
+		require.NoError(t, k.PlaceOrder(coldOrder))
+		_, err := k.PickUpOrder(coldOrder.ID)
+		require.NoError(t, err)
+
+		_, err = k.PickUpOrder(coldOrder.ID)
+		require.ErrorIs(t, err, ErrOrderNotFound)
+
+		status, ok := k.Status(coldOrder.ID)
+		require.True(t, ok)
+		require.Equal(t, OrderPickedUp, status.State)
+	})
+}
+
+func TestKitchen_Ledger(t *testing.T) {
+	t.Run("IsTotallyOrderedAndWithinCapacity_UnderConcurrency", func(t *testing.T) {
+		sink := NewMemorySink()
+		k := NewKitchen(2, 2, 3, 2, sink, NewRealClock())
+
+		temps := []Temperature{TemperatureHot, TemperatureCold, TemperatureRoom}
+		orders := make([]css.Order, 60)
+		for i := range orders {
+			orders[i] = css.Order{
+				ID:        fmt.Sprintf("order%d", i),
+				Name:      "Food",
+				Temp:      string(temps[i%len(temps)]),
+				Price:     1 + i%7,
+				Freshness: 600,
+			}
+		}
+
+		var wg sync.WaitGroup
+		for _, o := range orders {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				k.PlaceOrder(o)
+				k.PickUpOrder(o.ID)
+			}()
+		}
+		wg.Wait()
+
+		actions := sink.Actions()
+		for i := 1; i < len(actions); i++ {
+			require.GreaterOrEqual(t, actions[i].Timestamp, actions[i-1].Timestamp)
+		}
+
+		report := validate.Validate(orders, css.Options{Max: time.Hour.Microseconds()}, actions, validate.Config{
+			HeaterCapacity: 2,
+			CoolerCapacity: 2,
+			ShelfCapacity:  3,
+			Decay:          2,
+		})
+		require.True(t, report.Passed(), report.String())
+	})
+
+	t.Run("StampsActionsWithTheTimeOfTheirStateChange", func(t *testing.T) {
+		sink := NewMemorySink()
+		clock := NewFakeClock(time.UnixMicro(1000))
+		k := NewKitchen(1, 1, 1, 2, sink, clock)
+
+		k.PlaceOrder(css.Order{ID: "room1", Name: "Bread", Temp: string(TemperatureRoom), Price: 1, Freshness: 60})
+		k.PlaceOrder(css.Order{ID: "room2", Name: "Chips", Temp: string(TemperatureRoom), Price: 1, Freshness: 60})
+		clock.Advance(10 * time.Second)
+		order, err := k.PickUpOrder("room2")
+		require.NoError(t, err)
+		require.Equal(t, 50, order.Freshness)
+
+		require.Equal(t, []css.Action{
+			{Timestamp: 1000, ID: "room1", Action: css.Place, Target: css.Shelf},
+			{Timestamp: 1000, ID: "room1", Action: css.Discard, Target: css.Shelf},
+			{Timestamp: 1000, ID: "room2", Action: css.Place, Target: css.Shelf},
+			{Timestamp: 10_001_000, ID: "room2", Action: css.Pickup, Target: css.Shelf},
+		}, sink.Actions())
+	})
+
+	t.Run("NeverGoesBackInTime_WhenClockDoes", func(t *testing.T) {
+		sink := NewMemorySink()
+		clock := NewFakeClock(time.UnixMicro(1000))
+		k := NewKitchen(1, 1, 1, 2, sink, clock)
+
+		k.PlaceOrder(css.Order{ID: "hot1", Name: "Pizza", Temp: string(TemperatureHot), Price: 1, Freshness: 60})
+		clock.Set(time.UnixMicro(500))
+		k.PickUpOrder("hot1")
+
+		require.Equal(t, []css.Action{
+			{Timestamp: 1000, ID: "hot1", Action: css.Place, Target: css.Heater},
+			{Timestamp: 1000, ID: "hot1", Action: css.Pickup, Target: css.Heater},
+		}, sink.Actions())
+	})
+}
+
+func TestKitchen_FreshnessAcrossMoves(t *testing.T) {
+	hot1 := css.Order{ID: "hot1", Name: "Hot Pizza", Temp: string(TemperatureHot), Price: 10, Freshness: 100}
+	hot2 := css.Order{ID: "hot2", Name: "Hot Soup", Temp: string(TemperatureHot), Price: 8, Freshness: 100}
+	cold1 := css.Order{ID: "cold1", Name: "Cold Salad", Temp: string(TemperatureCold), Price: 5, Freshness: 100}
+	cold2 := css.Order{ID: "cold2", Name: "Cold Drink", Temp: string(TemperatureCold), Price: 4, Freshness: 100}
+
+	t.Run("ShelfToHeater", func(t *testing.T) {
+		sink := NewMemorySink()
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(1, 0, 1, 3, sink, clock)
+		require.NoError(t, k.PlaceOrder(hot1))
+		require.NoError(t, k.PlaceOrder(hot2))
+		placedAt := clock.Now()
+
+		// 10s on the shelf at 3x, then hot2 moves to the heater to make room for cold1
+		clock.Advance(10 * time.Second)
+		_, err := k.PickUpOrder(hot1.ID)
+		require.NoError(t, err)
+		require.NoError(t, k.PlaceOrder(cold1))
+		require.Equal(t, css.Move, sink.Actions()[3].Action)
+
+		// 20s in the heater at 1x
+		clock.Advance(20 * time.Second)
+		heater, _ := k.Snapshot().Storage(css.Heater)
+		require.Equal(t, 50*time.Second, heater.Orders[0].Freshness)
+		require.Equal(t, placedAt, heater.Orders[0].PlacedAt)
+		require.Equal(t, placedAt.Add(10*time.Second), heater.Orders[0].StoredAt)
+
+		order, err := k.PickUpOrder(hot2.ID)
+		require.NoError(t, err)
+		require.Equal(t, 50, order.Freshness)
+	})
+
+	t.Run("ShelfToCooler", func(t *testing.T) {
+		sink := NewMemorySink()
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(0, 1, 1, 2, sink, clock)
+		require.NoError(t, k.PlaceOrder(cold1))
+		require.NoError(t, k.PlaceOrder(cold2))
+
+		// 15s on the shelf at 2x, then cold2 moves to the cooler to make room for hot1
+		clock.Advance(15 * time.Second)
+		_, err := k.PickUpOrder(cold1.ID)
+		require.NoError(t, err)
+		require.NoError(t, k.PlaceOrder(hot1))
+		require.Equal(t, css.Move, sink.Actions()[3].Action)
+
+		// 60s in the cooler at 1x
+		clock.Advance(60 * time.Second)
+		order, err := k.PickUpOrder(cold2.ID)
+		require.NoError(t, err)
+		require.Equal(t, 10, order.Freshness)
+
+		// Time on the shelf still counts once the order is in the cooler
+		require.NoError(t, k.PlaceOrder(cold1))
+		require.NoError(t, k.PlaceOrder(cold2))
+		clock.Advance(30 * time.Second)
+		_, err = k.PickUpOrder(cold1.ID)
+		require.NoError(t, err)
+		require.NoError(t, k.PlaceOrder(hot2))
+		clock.Advance(41 * time.Second)
+
+		_, err = k.PickUpOrder(cold2.ID)
+		var expired *ExpiredOrderError
+		require.ErrorAs(t, err, &expired)
+		require.Equal(t, -time.Second, expired.Freshness)
+	})
+}
+
+func TestKitchen_FrozenOrders(t *testing.T) {
+	frozen := func(id string) css.Order {
+		return css.Order{ID: id, Name: "Ice Cream", Temp: string(TemperatureFrozen), Price: 6, Freshness: 100}
+	}
+
+	t.Run("OverflowsToCoolerBeforeShelf", func(t *testing.T) {
+		sink := NewMemorySink()
+		clock := NewFakeClock(time.Now())
+		k, err := NewKitchenWithUnits(DefaultStorageUnits(1, 1, 1, 1, 2), sink, clock)
+		require.NoError(t, err)
+
+		for _, id := range []string{"frozen1", "frozen2", "frozen3"} {
+			require.NoError(t, k.PlaceOrder(frozen(id)))
+		}
+		require.Equal(t, []css.Action{
+			{ID: "frozen1", Action: css.Place, Target: css.Freezer},
+			{ID: "frozen2", Action: css.Place, Target: css.Cooler},
+			{ID: "frozen3", Action: css.Place, Target: css.Shelf},
+		}, withoutTimestamps(sink.Actions()))
+
+		// 1x in the freezer, 2x in the cooler and 4x on the shelf
+		clock.Advance(10 * time.Second)
+		for storage, expected := range map[string]time.Duration{
+			css.Freezer: 90 * time.Second,
+			css.Cooler:  80 * time.Second,
+			css.Shelf:   60 * time.Second,
+		} {
+			s, _ := k.Snapshot().Storage(storage)
+			require.Equal(t, expected, s.Orders[0].Freshness, storage)
+		}
+	})
+
+	t.Run("GoToCoolerWithoutFreezer", func(t *testing.T) {
+		sink := NewMemorySink()
+		k := NewKitchen(1, 1, 1, 2, sink, NewFakeClock(time.Now()))
+		require.NoError(t, k.PlaceOrder(frozen("frozen1")))
+
+		require.Equal(t, css.Cooler, sink.Actions()[0].Target)
+		_, ok := k.Snapshot().Storage(css.Freezer)
+		require.False(t, ok)
+	})
 }
@@ -0,0 +1,207 @@
+package kitchen
+
+import (
+	"fmt"
+	"slices"
+)
+
+// StorageState is the occupancy of a storage at the time of a placement decision.
+type StorageState struct {
+	Capacity int64
+	Count    int64
+}
+
+func (s StorageState) HasSpace() bool {
+	return s.Count < s.Capacity
+}
+
+// StorageView is a read-only view of a storage handed to a PlacementStrategy.
+type StorageView struct {
+	Name         string
+	Temperatures []Temperature
+	StorageState
+	Items []StoredOrder // ordered by the time orders were placed in the storage
+}
+
+// Accepts reports whether the storage takes orders of temp.
+func (s StorageView) Accepts(temp Temperature) bool {
+	return slices.Contains(s.Temperatures, temp)
+}
+
+// PlacementView is a read-only view of the kitchen handed to a PlacementStrategy.
+type PlacementView struct {
+	Storages []StorageView // in priority order
+}
+
+// Overflow returns the storage of last resort for orders of temp: the last one accepting them.
+func (v PlacementView) Overflow(temp Temperature) (StorageView, bool) {
+	for i := len(v.Storages) - 1; i >= 0; i-- {
+		if v.Storages[i].Accepts(temp) {
+			return v.Storages[i], true
+		}
+	}
+	return StorageView{}, false
+}
+
+// Move relocates an order to another storage accepting its temperature.
+type Move struct {
+	ID     string
+	Target string // name of the storage, client.Heater or client.Cooler in a default kitchen
+}
+
+// Placement is the decision of a PlacementStrategy for an incoming order.
+type Placement struct {
+	// Target is the name of the storage the order goes to. The kitchen falls back to the overflow
+	// storage of the order when the target is full or does not accept the order's temperature.
+	Target string
+
+	// Moves are made before placing the order in its overflow storage, to free up space. Moves
+	// that are no longer possible are skipped. If the overflow storage is still full afterwards,
+	// an order is discarded from it.
+	Moves []Move
+}
+
+// PlacementStrategy decides where an incoming order goes and which rebalancing moves to make.
+type PlacementStrategy interface {
+	Place(order KitchenOrder, view PlacementView) Placement
+}
+
+const (
+	PlacementDefault   = "default"
+	PlacementFreshness = "freshness"
+	PlacementNoMove    = "no-move"
+)
+
+// PlacementStrategyByName returns the built-in strategy registered under name.
+func PlacementStrategyByName(name string) (PlacementStrategy, error) {
+	switch name {
+	case PlacementDefault:
+		return DefaultPlacement{}, nil
+	case PlacementFreshness:
+		return FreshnessPlacement{}, nil
+	case PlacementNoMove:
+		return NoMovePlacement{}, nil
+	default:
+		return nil, fmt.Errorf(
+			"unknown placement strategy %q, must be one of %s, %s or %s",
+			name, PlacementDefault, PlacementFreshness, PlacementNoMove,
+		)
+	}
+}
+
+// -- Ideal storage, then overflow, then move the oldest order of another temperature --
+
+type DefaultPlacement struct{}
+
+func (DefaultPlacement) Place(order KitchenOrder, view PlacementView) Placement {
+	overflow, ok := view.Overflow(order.Temperature)
+	if !ok {
+		return Placement{}
+	}
+
+	if target, ok := idealTarget(order.Temperature, view); ok {
+		return Placement{Target: target}
+	}
+
+	if overflow.HasSpace() {
+		return Placement{Target: overflow.Name}
+	}
+
+	// An order with a storage of its own makes room by moving the oldest order of another
+	// temperature to its own storage: a cold order moves a hot order to the heater, and the
+	// other way around
+	if !hasIdealStorage(order.Temperature, view) {
+		return Placement{Target: overflow.Name}
+	}
+
+	for _, item := range overflow.Items {
+		if item.Temperature == order.Temperature {
+			continue
+		}
+		if target, ok := idealTarget(item.Temperature, view); ok {
+			return Placement{Target: overflow.Name, Moves: []Move{{ID: item.ID, Target: target}}}
+		}
+	}
+
+	return Placement{Target: overflow.Name}
+}
+
+// -- Ideal storage, then overflow, then move the movable order with the least freshness --
+
+type FreshnessPlacement struct{}
+
+func (FreshnessPlacement) Place(order KitchenOrder, view PlacementView) Placement {
+	overflow, ok := view.Overflow(order.Temperature)
+	if !ok {
+		return Placement{}
+	}
+
+	if target, ok := idealTarget(order.Temperature, view); ok {
+		return Placement{Target: target}
+	}
+
+	if overflow.HasSpace() {
+		return Placement{Target: overflow.Name}
+	}
+
+	var best *StoredOrder
+	var bestTarget string
+	for i := range overflow.Items {
+		item := &overflow.Items[i]
+		target, ok := idealTarget(item.Temperature, view)
+		if !ok {
+			continue
+		}
+		if best == nil || item.Freshness < best.Freshness {
+			best, bestTarget = item, target
+		}
+	}
+
+	if best == nil {
+		return Placement{Target: overflow.Name}
+	}
+
+	return Placement{Target: overflow.Name, Moves: []Move{{ID: best.ID, Target: bestTarget}}}
+}
+
+// -- Ideal storage, then overflow, never move --
+
+type NoMovePlacement struct{}
+
+func (NoMovePlacement) Place(order KitchenOrder, view PlacementView) Placement {
+	if target, ok := idealTarget(order.Temperature, view); ok {
+		return Placement{Target: target}
+	}
+	if overflow, ok := view.Overflow(order.Temperature); ok {
+		return Placement{Target: overflow.Name}
+	}
+	return Placement{}
+}
+
+// idealTarget returns the first storage ahead of the overflow for temp that has space.
+func idealTarget(temp Temperature, view PlacementView) (string, bool) {
+	overflow, _ := view.Overflow(temp)
+	for _, storage := range view.Storages {
+		if storage.Name == overflow.Name {
+			break
+		}
+		if storage.Accepts(temp) && storage.HasSpace() {
+			return storage.Name, true
+		}
+	}
+	return "", false
+}
+
+// hasIdealStorage reports whether orders of temp have a storage ahead of their overflow.
+func hasIdealStorage(temp Temperature, view PlacementView) bool {
+	overflow, _ := view.Overflow(temp)
+	for _, storage := range view.Storages {
+		if storage.Name == overflow.Name {
+			return false
+		}
+		if storage.Accepts(temp) {
+			return true
+		}
+	}
+	return false
+}
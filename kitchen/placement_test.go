@@ -0,0 +1,152 @@
+package kitchen
+
+import (
+	"testing"
+	"time"
+
+	css "challenge/client"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlacementStrategies(t *testing.T) {
+	now := time.Now()
+	full := StorageState{Capacity: 1, Count: 1}
+	free := StorageState{Capacity: 1, Count: 0}
+
+	shelfItems := []StoredOrder{
+		{ID: "hot1", Temperature: TemperatureHot, StoredAt: now, Freshness: 40 * time.Second},
+		{ID: "cold1", Temperature: TemperatureCold, StoredAt: now.Add(time.Second), Freshness: 10 * time.Second},
+		{ID: "hot2", Temperature: TemperatureHot, StoredAt: now.Add(2 * time.Second), Freshness: 20 * time.Second},
+	}
+
+	defaultView := func(heater, cooler, shelf StorageState, shelfItems []StoredOrder) PlacementView {
+		units := DefaultStorageUnits(heater.Capacity, cooler.Capacity, 0, shelf.Capacity, 2)
+		return PlacementView{Storages: []StorageView{
+			{Name: css.Heater, Temperatures: units[0].Temperatures, StorageState: heater},
+			{Name: css.Cooler, Temperatures: units[1].Temperatures, StorageState: cooler},
+			{Name: css.Shelf, Temperatures: units[2].Temperatures, StorageState: shelf, Items: shelfItems},
+		}}
+	}
+
+	hot := KitchenOrder{ID: "new", Temperature: TemperatureHot}
+	cold := KitchenOrder{ID: "new", Temperature: TemperatureCold}
+	room := KitchenOrder{ID: "new", Temperature: TemperatureRoom}
+
+	testCases := []struct {
+		name     string
+		strategy PlacementStrategy
+		order    KitchenOrder
+		view     PlacementView
+		expected Placement
+	}{
+		{
+			name:     "Default/PlacesInIdealStorage_WhenItHasSpace",
+			strategy: DefaultPlacement{},
+			order:    hot,
+			view:     defaultView(free, full, full, shelfItems),
+			expected: Placement{Target: css.Heater},
+		},
+		{
+			name:     "Default/PlacesOnShelf_WhenShelfHasSpace",
+			strategy: DefaultPlacement{},
+			order:    cold,
+			view:     defaultView(free, full, free, nil),
+			expected: Placement{Target: css.Shelf},
+		},
+		{
+			name:     "Default/MovesOldestHotOrder_WhenColdOrderOverflows",
+			strategy: DefaultPlacement{},
+			order:    cold,
+			view:     defaultView(free, full, full, shelfItems),
+			expected: Placement{Target: css.Shelf, Moves: []Move{{ID: "hot1", Target: css.Heater}}},
+		},
+		{
+			name:     "Default/DoesNotMove_WhenRoomOrderOverflows",
+			strategy: DefaultPlacement{},
+			order:    room,
+			view:     defaultView(free, free, full, shelfItems),
+			expected: Placement{Target: css.Shelf},
+		},
+		{
+			name:     "Freshness/MovesLeastFreshMovableOrder",
+			strategy: FreshnessPlacement{},
+			order:    room,
+			view:     defaultView(free, free, full, shelfItems),
+			expected: Placement{Target: css.Shelf, Moves: []Move{{ID: "cold1", Target: css.Cooler}}},
+		},
+		{
+			name:     "Freshness/SkipsOrdersWithFullIdealStorage",
+			strategy: FreshnessPlacement{},
+			order:    room,
+			view:     defaultView(free, full, full, shelfItems),
+			expected: Placement{Target: css.Shelf, Moves: []Move{{ID: "hot2", Target: css.Heater}}},
+		},
+		{
+			name:     "NoMove/NeverMoves",
+			strategy: NoMovePlacement{},
+			order:    cold,
+			view:     defaultView(free, full, full, shelfItems),
+			expected: Placement{Target: css.Shelf},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.strategy.Place(tc.order, tc.view))
+		})
+	}
+
+	t.Run("ByName", func(t *testing.T) {
+		for _, name := range []string{PlacementDefault, PlacementFreshness, PlacementNoMove} {
+			_, err := PlacementStrategyByName(name)
+			require.NoError(t, err)
+		}
+
+		_, err := PlacementStrategyByName("random")
+		require.ErrorContains(t, err, `unknown placement strategy "random"`)
+	})
+}
+
+func TestKitchen_WithPlacementStrategy(t *testing.T) {
+	sink := NewMemorySink()
+
+	hotOrder := css.Order{ID: "hot1", Name: "Hot Pizza", Temp: string(TemperatureHot), Price: 10, Freshness: 600}
+	coldOrder := css.Order{ID: "cold1", Name: "Cold Salad", Temp: string(TemperatureCold), Price: 5, Freshness: 600}
+	coldOrder2 := css.Order{ID: "cold2", Name: "Cold Drink", Temp: string(TemperatureCold), Price: 5, Freshness: 600}
+	roomOrder := css.Order{ID: "room1", Name: "Room Bread", Temp: string(TemperatureRoom), Price: 5, Freshness: 600}
+
+	t.Run("NoMove_DiscardsInsteadOfMoving", func(t *testing.T) {
+		k := NewKitchen(1, 1, 1, 2, sink, NewFakeClock(time.Now()), WithPlacementStrategy(NoMovePlacement{}))
+
+		require.NoError(t, k.PlaceOrder(coldOrder))
+		require.NoError(t, k.PlaceOrder(hotOrder))
+		require.NoError(t, k.PlaceOrder(coldOrder2))
+		require.NoError(t, k.PlaceOrder(roomOrder))
+
+		_, err := k.PickUpOrder(coldOrder2.ID)
+		require.Error(t, err)
+
+		_, err = k.PickUpOrder(roomOrder.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("Freshness_MovesShelfOrderForRoomOrder", func(t *testing.T) {
+		k := NewKitchen(1, 1, 1, 2, sink, NewFakeClock(time.Now()), WithPlacementStrategy(FreshnessPlacement{}))
+
+		require.NoError(t, k.PlaceOrder(coldOrder))
+		require.NoError(t, k.PlaceOrder(coldOrder2))
+		_, err := k.PickUpOrder(coldOrder.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, k.PlaceOrder(roomOrder))
+
+		require.Equal(t, int64(1), storageOf(k, css.Cooler).Len())
+		require.Equal(t, int64(1), storageOf(k, css.Shelf).Len())
+
+		_, err = k.PickUpOrder(coldOrder2.ID)
+		require.NoError(t, err)
+		_, err = k.PickUpOrder(roomOrder.ID)
+		require.NoError(t, err)
+	})
+}
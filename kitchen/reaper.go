@@ -0,0 +1,49 @@
+package kitchen
+
+import (
+	"challenge/client"
+	"context"
+	"time"
+)
+
+// Reap discards every order whose freshness has run out, in any storage, and returns how many
+// orders were discarded. This frees capacity for new orders.
+func (k *Kitchen) Reap() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.journalErr != nil {
+		return 0
+	}
+
+	discarded := 0
+	for _, u := range k.units {
+		for _, id := range u.store.Expired() {
+			order, ok := u.store.Get(id)
+			if !ok {
+				continue
+			}
+			if err := k.remove(client.Discard, order, u, k.now()); err != nil {
+				return discarded
+			}
+			discarded++
+		}
+	}
+
+	return discarded
+}
+
+// RunReaper calls Reap every interval until ctx is done. It blocks, so run it in its own goroutine.
+func (k *Kitchen) RunReaper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			k.Reap()
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package kitchen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	css "challenge/client"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKitchen_Reap(t *testing.T) {
+	orders := []css.Order{
+		{ID: "hot1", Name: "Hot Pizza", Temp: string(TemperatureHot), Price: 10, Freshness: 5},
+		{ID: "cold1", Name: "Cold Salad", Temp: string(TemperatureCold), Price: 5, Freshness: 60},
+		{ID: "cold2", Name: "Cold Drink", Temp: string(TemperatureCold), Price: 5, Freshness: 5},
+		{ID: "room1", Name: "Room Bread", Temp: string(TemperatureRoom), Price: 5, Freshness: 8},
+	}
+
+	t.Run("DiscardsExpiredOrdersInEveryStorage", func(t *testing.T) {
+		sink := NewMemorySink()
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(1, 1, 2, 2, sink, clock)
+
+		for _, o := range orders {
+			require.NoError(t, k.PlaceOrder(o))
+		}
+		require.Equal(t, int64(2), storageOf(k, css.Shelf).Len())
+
+		// cold2 decays twice as fast on the shelf, the others are still fresh
+		clock.Advance(3 * time.Second)
+		require.Equal(t, 1, k.Reap())
+		require.Equal(t, int64(1), storageOf(k, css.Shelf).Len())
+
+		// hot1 runs out in the heater, room1 still has 2s left
+		clock.Advance(3 * time.Second)
+		require.Equal(t, 1, k.Reap())
+		require.Zero(t, storageOf(k, css.Heater).Len())
+
+		clock.Advance(3 * time.Second)
+		require.Equal(t, 1, k.Reap())
+		require.Zero(t, storageOf(k, css.Shelf).Len())
+		require.Equal(t, int64(1), storageOf(k, css.Cooler).Len())
+
+		require.Equal(t, []css.Action{
+			{ID: "hot1", Action: css.Place, Target: css.Heater},
+			{ID: "cold1", Action: css.Place, Target: css.Cooler},
+			{ID: "cold2", Action: css.Place, Target: css.Shelf},
+			{ID: "room1", Action: css.Place, Target: css.Shelf},
+			{ID: "cold2", Action: css.Discard, Target: css.Shelf},
+			{ID: "hot1", Action: css.Discard, Target: css.Heater},
+			{ID: "room1", Action: css.Discard, Target: css.Shelf},
+		}, withoutTimestamps(sink.Actions()))
+
+		// Freed capacity is available to new orders
+		require.True(t, storageOf(k, css.Heater).HasSpace())
+		require.True(t, storageOf(k, css.Shelf).HasSpace())
+	})
+
+	t.Run("RunReaper_StopsWhenContextIsDone", func(t *testing.T) {
+		sink := NewMemorySink()
+		clock := NewFakeClock(time.Now())
+		k := NewKitchen(1, 1, 1, 2, sink, clock)
+		require.NoError(t, k.PlaceOrder(orders[0]))
+		clock.Advance(time.Minute)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			k.RunReaper(ctx, time.Millisecond)
+			close(done)
+		}()
+
+		require.Eventually(t, func() bool {
+			return storageOf(k, css.Heater).HasSpace()
+		}, time.Second, time.Millisecond)
+
+		cancel()
+		<-done
+		require.Len(t, sink.Actions(), 2)
+		require.Equal(t, css.Discard, sink.Actions()[1].Action)
+	})
+}
@@ -0,0 +1,160 @@
+package kitchen
+
+import (
+	"challenge/client"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// ActionSink receives every place, move, pickup and discard performed by the kitchen. Emit is called
+// while the kitchen is locked, so it must not call back into the kitchen.
+type ActionSink interface {
+	Emit(action client.Action)
+}
+
+// -- In-memory sink, collects the ledger --
+
+type MemorySink struct {
+	actions []client.Action
+	mu      sync.Mutex
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Emit(action client.Action) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions = append(s.actions, action)
+}
+
+// Actions returns a copy of the actions emitted so far.
+func (s *MemorySink) Actions() []client.Action {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]client.Action(nil), s.actions...)
+}
+
+// -- Channel sink, blocks the kitchen until the action is received --
+
+type ChannelSink struct {
+	ch chan<- client.Action
+}
+
+func NewChannelSink(ch chan<- client.Action) *ChannelSink {
+	return &ChannelSink{ch: ch}
+}
+
+func (s *ChannelSink) Emit(action client.Action) {
+	s.ch <- action
+}
+
+// -- JSONL sink, writes one action per line --
+
+type JSONLSink struct {
+	enc *json.Encoder
+	err error
+	mu  sync.Mutex
+}
+
+func NewJSONLSink(w io.Writer) *JSONLSink {
+	return &JSONLSink{enc: json.NewEncoder(w)}
+}
+
+func (s *JSONLSink) Emit(action client.Action) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return
+	}
+	s.err = s.enc.Encode(action)
+}
+
+// Err returns the first write error, after which nothing more is written.
+func (s *JSONLSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// -- Broadcast sink, fans out to subscribers without blocking the kitchen --
+
+type BroadcastSink struct {
+	buffer      int
+	subscribers map[chan client.Action]struct{}
+	closed      bool
+	mu          sync.Mutex
+}
+
+// NewBroadcastSink returns a sink that sends every action to its current subscribers. Each
+// subscriber has a buffer of the given size; a subscriber that falls further behind is dropped
+// and its channel closed, rather than holding up the kitchen.
+func NewBroadcastSink(buffer int) *BroadcastSink {
+	return &BroadcastSink{
+		buffer:      buffer,
+		subscribers: make(map[chan client.Action]struct{}),
+	}
+}
+
+func (s *BroadcastSink) Emit(action client.Action) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- action:
+		default:
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns a channel receiving every action emitted from now on, and a function to
+// unsubscribe. The channel is closed when the subscriber is dropped or the sink is closed.
+func (s *BroadcastSink) Subscribe() (<-chan client.Action, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan client.Action, s.buffer)
+	if s.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	s.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close closes every subscriber channel. Later subscribers get a closed channel.
+func (s *BroadcastSink) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.subscribers {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+	s.closed = true
+}
+
+// -- Fan-out to several sinks --
+
+type MultiSink []ActionSink
+
+func (m MultiSink) Emit(action client.Action) {
+	for _, sink := range m {
+		sink.Emit(action)
+	}
+}
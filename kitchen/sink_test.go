@@ -0,0 +1,149 @@
+package kitchen
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	css "challenge/client"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestSinks(t *testing.T) {
+	actions := []css.Action{
+		{Timestamp: 1, ID: "a1", Action: css.Place, Target: css.Heater},
+		{Timestamp: 2, ID: "a1", Action: css.Pickup, Target: css.Heater},
+	}
+
+	t.Run("MemorySink_CollectsActions", func(t *testing.T) {
+		s := NewMemorySink()
+		for _, a := range actions {
+			s.Emit(a)
+		}
+
+		got := s.Actions()
+		require.Equal(t, actions, got)
+
+		// The returned slice is a copy
+		got[0].ID = "changed"
+		require.Equal(t, actions, s.Actions())
+	})
+
+	t.Run("ChannelSink_SendsActions", func(t *testing.T) {
+		ch := make(chan css.Action, len(actions))
+		s := NewChannelSink(ch)
+		for _, a := range actions {
+			s.Emit(a)
+		}
+
+		require.Equal(t, actions[0], <-ch)
+		require.Equal(t, actions[1], <-ch)
+	})
+
+	t.Run("JSONLSink_WritesOneActionPerLine", func(t *testing.T) {
+		var buf bytes.Buffer
+		s := NewJSONLSink(&buf)
+		for _, a := range actions {
+			s.Emit(a)
+		}
+
+		require.NoError(t, s.Err())
+		require.Equal(t,
+			`{"timestamp":1,"id":"a1","action":"place","target":"heater"}`+"\n"+
+				`{"timestamp":2,"id":"a1","action":"pickup","target":"heater"}`+"\n",
+			buf.String(),
+		)
+	})
+
+	t.Run("JSONLSink_KeepsFirstError", func(t *testing.T) {
+		s := NewJSONLSink(failingWriter{})
+		s.Emit(actions[0])
+		s.Emit(actions[1])
+		require.EqualError(t, s.Err(), "disk full")
+	})
+
+	t.Run("MultiSink_FansOut", func(t *testing.T) {
+		first, second := NewMemorySink(), NewMemorySink()
+		s := MultiSink{first, second}
+		for _, a := range actions {
+			s.Emit(a)
+		}
+
+		require.Equal(t, actions, first.Actions())
+		require.Equal(t, actions, second.Actions())
+	})
+
+	t.Run("BroadcastSink_SendsToSubscribers", func(t *testing.T) {
+		s := NewBroadcastSink(len(actions))
+		first, _ := s.Subscribe()
+		second, unsubscribe := s.Subscribe()
+		unsubscribe()
+		unsubscribe()
+
+		for _, a := range actions {
+			s.Emit(a)
+		}
+
+		require.Equal(t, actions[0], <-first)
+		require.Equal(t, actions[1], <-first)
+		_, ok := <-second
+		require.False(t, ok)
+	})
+
+	t.Run("BroadcastSink_DropsSlowSubscriber", func(t *testing.T) {
+		s := NewBroadcastSink(1)
+		slow, _ := s.Subscribe()
+
+		for _, a := range actions {
+			s.Emit(a)
+		}
+
+		require.Equal(t, actions[0], <-slow)
+		_, ok := <-slow
+		require.False(t, ok)
+	})
+
+	t.Run("BroadcastSink_CloseEndsSubscriptions", func(t *testing.T) {
+		s := NewBroadcastSink(1)
+		before, _ := s.Subscribe()
+		s.Close()
+		after, _ := s.Subscribe()
+
+		_, ok := <-before
+		require.False(t, ok)
+		_, ok = <-after
+		require.False(t, ok)
+	})
+}
+
+func TestKitchen_Emit(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	order := css.Order{ID: "hot1", Name: "Hot Pizza", Temp: string(TemperatureHot), Price: 10, Freshness: 600}
+
+	var buf bytes.Buffer
+	sink := NewMemorySink()
+	clock := NewFakeClock(start)
+	k := NewKitchen(1, 1, 1, 2, sink, clock, WithLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+
+	require.NoError(t, k.PlaceOrder(order))
+	clock.Advance(5 * time.Second)
+	_, err := k.PickUpOrder(order.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, []css.Action{
+		{Timestamp: start.UnixMicro(), ID: "hot1", Action: css.Place, Target: css.Heater},
+		{Timestamp: start.Add(5 * time.Second).UnixMicro(), ID: "hot1", Action: css.Pickup, Target: css.Heater},
+	}, sink.Actions())
+
+	require.Contains(t, buf.String(), `msg=place "order id"=hot1 target=heater`)
+	require.Contains(t, buf.String(), `msg=pickup "order id"=hot1 target=heater`)
+}
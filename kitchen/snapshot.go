@@ -0,0 +1,48 @@
+package kitchen
+
+import "time"
+
+// StorageSnapshot is the content of a storage at a point in time.
+type StorageSnapshot struct {
+	Name      string
+	Capacity  int64
+	Occupancy int64
+	Orders    []StoredOrder // ordered by the time orders were placed in the storage
+}
+
+// Snapshot is a consistent, point-in-time view of every storage in the kitchen.
+type Snapshot struct {
+	Time     time.Time
+	Storages []StorageSnapshot // in priority order
+}
+
+// Snapshot returns the content of the kitchen. Remaining freshness is computed at the time of the
+// snapshot, and no order is placed, moved or removed while it is taken.
+func (k *Kitchen) Snapshot() Snapshot {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.clock.Now()
+
+	snapshot := Snapshot{Time: now, Storages: make([]StorageSnapshot, len(k.units))}
+	for i, u := range k.units {
+		state := u.store.State()
+		snapshot.Storages[i] = StorageSnapshot{
+			Name:      u.Name,
+			Capacity:  state.Capacity,
+			Occupancy: state.Count,
+			Orders:    u.store.itemsAt(now),
+		}
+	}
+	return snapshot
+}
+
+// Storage returns the snapshot of the named storage.
+func (s Snapshot) Storage(name string) (StorageSnapshot, bool) {
+	for _, storage := range s.Storages {
+		if storage.Name == name {
+			return storage, true
+		}
+	}
+	return StorageSnapshot{}, false
+}
@@ -0,0 +1,123 @@
+package kitchen
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	css "challenge/client"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKitchen_Snapshot(t *testing.T) {
+	t.Run("ReturnsContentOfEveryStorage", func(t *testing.T) {
+		start := time.Unix(1_700_000_000, 0)
+		clock := NewFakeClock(start)
+		k := NewKitchen(1, 2, 2, 2, NewMemorySink(), clock)
+
+		k.PlaceOrder(css.Order{ID: "hot1", Name: "Hot Pizza", Temp: string(TemperatureHot), Price: 10, Freshness: 60})
+		clock.Advance(time.Second)
+		k.PlaceOrder(css.Order{ID: "hot2", Name: "Hot Soup", Temp: string(TemperatureHot), Price: 8, Freshness: 60})
+		clock.Advance(time.Second)
+		k.PlaceOrder(css.Order{ID: "room1", Name: "Room Bread", Temp: string(TemperatureRoom), Price: 3, Freshness: 60})
+		clock.Advance(10 * time.Second)
+
+		snapshot := k.Snapshot()
+
+		require.Equal(t, start.Add(12*time.Second), snapshot.Time)
+		require.Equal(t, Snapshot{
+			Time: start.Add(12 * time.Second),
+			Storages: []StorageSnapshot{
+				{
+					Name:      css.Heater,
+					Capacity:  1,
+					Occupancy: 1,
+					Orders: []StoredOrder{{
+						ID:          "hot1",
+						Name:        "Hot Pizza",
+						Temperature: TemperatureHot,
+						Price:       10,
+						PlacedAt:    start,
+						StoredAt:    start,
+						Freshness:   48 * time.Second,
+					}},
+				},
+				{
+					Name:      css.Cooler,
+					Capacity:  2,
+					Occupancy: 0,
+					Orders:    []StoredOrder{},
+				},
+				{
+					Name:      css.Shelf,
+					Capacity:  2,
+					Occupancy: 2,
+					Orders: []StoredOrder{
+						{
+							ID:          "hot2",
+							Name:        "Hot Soup",
+							Temperature: TemperatureHot,
+							Price:       8,
+							PlacedAt:    start.Add(time.Second),
+							StoredAt:    start.Add(time.Second),
+							Freshness:   38 * time.Second,
+						},
+						{
+							ID:          "room1",
+							Name:        "Room Bread",
+							Temperature: TemperatureRoom,
+							Price:       3,
+							PlacedAt:    start.Add(2 * time.Second),
+							StoredAt:    start.Add(2 * time.Second),
+							Freshness:   50 * time.Second,
+						},
+					},
+				},
+			},
+		}, snapshot)
+
+		shelf, ok := snapshot.Storage(css.Shelf)
+		require.True(t, ok)
+		require.Equal(t, int64(2), shelf.Occupancy)
+
+		_, ok = snapshot.Storage("freezer")
+		require.False(t, ok)
+	})
+
+	t.Run("IsConsistent_UnderConcurrency", func(t *testing.T) {
+		k := NewKitchen(3, 3, 4, 2, NewMemorySink(), NewRealClock())
+
+		var wg sync.WaitGroup
+		for i := range 40 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				id := string(rune('a'+i%26)) + string(rune('a'+i/26))
+				temp := []Temperature{TemperatureHot, TemperatureCold, TemperatureRoom}[i%3]
+				k.PlaceOrder(css.Order{ID: id, Name: "Food", Temp: string(temp), Price: 1, Freshness: 60})
+				k.PickUpOrder(id)
+			}()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		for {
+			snapshot := k.Snapshot()
+			for _, storage := range snapshot.Storages {
+				require.Len(t, storage.Orders, int(storage.Occupancy))
+				require.LessOrEqual(t, storage.Occupancy, storage.Capacity)
+			}
+
+			select {
+			case <-done:
+				return
+			default:
+			}
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package kitchen
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// stateVersion is the version of the format written by Save. Load rejects other versions.
+const stateVersion = 3
+
+type savedOrder struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Temperature Temperature   `json:"temperature"`
+	Price       int           `json:"price"`
+	Freshness   time.Duration `json:"freshness"`       // freshness when placed, in nanoseconds
+	CookedAt    time.Time     `json:"cookedAt"`        // when the order was placed in the kitchen
+	StoredAt    time.Time     `json:"storedAt"`        // when the order was put in its storage
+	Spent       time.Duration `json:"spent,omitempty"` // freshness spent in previous storages
+}
+
+type savedUnit struct {
+	unitConfig
+	Orders []savedOrder `json:"orders"`
+}
+
+type savedKitchen struct {
+	Version    int         `json:"version"`
+	SavedAt    time.Time   `json:"savedAt"`
+	LastAction int64       `json:"lastAction"`
+	Storages   []savedUnit `json:"storages"`
+}
+
+// Save writes the full state of the kitchen to w: its storage units, the content of each in the
+// order it was stored and the freshness of every order. A kitchen restored with Load behaves like
+// this one. Decay models other than the built-in ones are not saved.
+func (k *Kitchen) Save(w io.Writer) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	state := savedKitchen{
+		Version:    stateVersion,
+		SavedAt:    k.clock.Now(),
+		LastAction: k.lastAction,
+		Storages:   make([]savedUnit, len(k.units)),
+	}
+	for i, u := range k.units {
+		state.Storages[i] = savedUnit{unitConfig: u.config(), Orders: saveOrders(u.store.orders())}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(state)
+}
+
+// Load restores a kitchen written by Save. Storage units come from the saved state, the sink,
+// clock and options are those of the new kitchen. Pass WithDecayModel for decay models that
+// were not saved.
+func Load(r io.Reader, sink ActionSink, clock Clock, opts ...Option) (*Kitchen, error) {
+	var state savedKitchen
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return nil, fmt.Errorf("failed to read kitchen state: %v", err)
+	}
+
+	if state.Version != stateVersion {
+		return nil, fmt.Errorf("unsupported kitchen state version %d, expected %d", state.Version, stateVersion)
+	}
+
+	units, contents, err := state.units()
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := NewKitchenWithUnits(units, sink, clock, opts...)
+	if err != nil {
+		return nil, err
+	}
+	k.lastAction = state.LastAction
+
+	// An order is in a single storage
+	stored := make(map[string]string)
+	for i, orders := range contents {
+		u, _ := k.unit(units[i].Name)
+		for _, saved := range orders {
+			if other, ok := stored[saved.ID]; ok {
+				return nil, fmt.Errorf("%s: order %s already in %s", u.Name, saved.ID, other)
+			}
+			stored[saved.ID] = u.Name
+
+			if !u.accepts(saved.Temperature) {
+				return nil, fmt.Errorf("%s: %s order %s not accepted", u.Name, saved.Temperature, saved.ID)
+			}
+			if err := u.store.put(restoreOrder(saved)); err != nil {
+				return nil, fmt.Errorf("%s: %v", u.Name, err)
+			}
+		}
+
+		// The history of restored orders is a single placement, when they were cooked, in the
+		// storage holding them now
+		k.trackRestored(u.Name, orders)
+	}
+
+	return k, nil
+}
+
+// units returns the storage units of a saved kitchen and their content.
+func (state savedKitchen) units() ([]StorageUnit, [][]savedOrder, error) {
+	units := make([]StorageUnit, len(state.Storages))
+	contents := make([][]savedOrder, len(state.Storages))
+	for i, saved := range state.Storages {
+		u, err := saved.unit()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", saved.Name, err)
+		}
+		units[i], contents[i] = u, saved.Orders
+	}
+	return units, contents, nil
+}
+
+func (k *Kitchen) trackRestored(target string, orders []savedOrder) {
+	for _, saved := range orders {
+		k.orders[saved.ID] = &OrderStatus{
+			ID:       saved.ID,
+			Order:    placedOrder(restoreOrder(saved)),
+			State:    OrderPlaced,
+			Location: target,
+			History:  []Transition{{State: OrderPlaced, Target: target, Time: saved.CookedAt}},
+		}
+	}
+}
+
+// -- Storage state --
+
+// orders returns the orders in the storage, in the order they were stored.
+func (s *Storage) orders() []*KitchenOrder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]*KitchenOrder, 0, len(s.items))
+	for _, order := range s.items {
+		orders = append(orders, order)
+	}
+	sortOrders(orders)
+	return orders
+}
+
+// put stores an order as is, keeping its storage time and freshness.
+func (s *Storage) put(order *KitchenOrder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == s.capacity {
+		return fmt.Errorf("more orders than capacity %d", s.capacity)
+	}
+	if _, ok := s.items[order.ID]; ok {
+		return fmt.Errorf("duplicate order %s", order.ID)
+	}
+
+	s.items[order.ID] = order
+	s.count++
+	return nil
+}
+
+// take removes an order as is, without updating its freshness.
+func (s *Storage) take(orderid string) (*KitchenOrder, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.items[orderid]
+	if !ok {
+		return nil, false
+	}
+
+	delete(s.items, orderid)
+	s.count--
+	return order, true
+}
+
+// orders returns the orders on the shelf, in the order they were stored within each temperature.
+func (s *ShelfStorage) orders() []*KitchenOrder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var orders []*KitchenOrder
+	for _, l := range []*list.List{s.coldItems, s.hotItems, s.roomItems, s.frozenItems} {
+		orders = append(orders, listOrders(l)...)
+	}
+	return orders
+}
+
+// put stores an order as is at the back of its list, keeping its storage time and freshness.
+func (s *ShelfStorage) put(order *KitchenOrder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == s.capacity {
+		return fmt.Errorf("more orders than capacity %d", s.capacity)
+	}
+	if _, ok := s.items[order.ID]; ok {
+		return fmt.Errorf("duplicate order %s", order.ID)
+	}
+
+	s.items[order.ID] = s.list(order.Temperature).PushBack(order)
+	s.count++
+	return nil
+}
+
+// take removes an order as is, without updating its freshness.
+func (s *ShelfStorage) take(orderid string) (*KitchenOrder, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[orderid]
+	if !ok {
+		return nil, false
+	}
+
+	order := el.Value.(*KitchenOrder)
+	s.list(order.Temperature).Remove(el)
+	delete(s.items, orderid)
+	s.count--
+	return order, true
+}
+
+func (s *ShelfStorage) list(temp Temperature) *list.List {
+	switch temp {
+	case TemperatureCold:
+		return s.coldItems
+	case TemperatureHot:
+		return s.hotItems
+	case TemperatureFrozen:
+		return s.frozenItems
+	default:
+		return s.roomItems
+	}
+}
+
+// -- Helper Functions --
+
+func listOrders(l *list.List) []*KitchenOrder {
+	orders := make([]*KitchenOrder, 0, l.Len())
+	for el := l.Front(); el != nil; el = el.Next() {
+		orders = append(orders, el.Value.(*KitchenOrder))
+	}
+	return orders
+}
+
+func saveOrders(orders []*KitchenOrder) []savedOrder {
+	saved := make([]savedOrder, len(orders))
+	for i, order := range orders {
+		saved[i] = savedOrder{
+			ID:          order.ID,
+			Name:        order.Name,
+			Temperature: order.Temperature,
+			Price:       order.Price,
+			Freshness:   order.Freshness,
+			CookedAt:    order.cookedAt,
+			StoredAt:    order.storedAt,
+			Spent:       order.spent,
+		}
+	}
+	return saved
+}
+
+func restoreOrder(saved savedOrder) *KitchenOrder {
+	return &KitchenOrder{
+		ID:          saved.ID,
+		Name:        saved.Name,
+		Temperature: saved.Temperature,
+		Price:       saved.Price,
+		Freshness:   saved.Freshness,
+		cookedAt:    saved.CookedAt,
+		storedAt:    saved.StoredAt,
+		spent:       saved.Spent,
+	}
+}
@@ -0,0 +1,133 @@
+package kitchen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	css "challenge/client"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKitchen_SaveLoad(t *testing.T) {
+	orders := []css.Order{
+		{ID: "hot1", Name: "Hot Pizza", Temp: string(TemperatureHot), Price: 10, Freshness: 60},
+		{ID: "cold1", Name: "Cold Salad", Temp: string(TemperatureCold), Price: 5, Freshness: 90},
+		{ID: "cold2", Name: "Cold Drink", Temp: string(TemperatureCold), Price: 4, Freshness: 50},
+		{ID: "room1", Name: "Room Bread", Temp: string(TemperatureRoom), Price: 3, Freshness: 40},
+		{ID: "hot2", Name: "Hot Soup", Temp: string(TemperatureHot), Price: 8, Freshness: 70},
+	}
+
+	start := time.Unix(1_700_000_000, 0).UTC()
+
+	newKitchen := func(t *testing.T) (*Kitchen, *FakeClock) {
+		clock := NewFakeClock(start)
+		k := NewKitchen(1, 1, 3, 2, NewMemorySink(), clock)
+		for _, o := range orders {
+			require.NoError(t, k.PlaceOrder(o))
+			clock.Advance(time.Second)
+		}
+		return k, clock
+	}
+
+	t.Run("RestoredKitchenBehavesLikeTheOriginal", func(t *testing.T) {
+		original, clock := newKitchen(t)
+
+		var buf bytes.Buffer
+		require.NoError(t, original.Save(&buf))
+
+		restoredClock := NewFakeClock(clock.Now())
+		restored, err := Load(&buf, NewMemorySink(), restoredClock)
+		require.NoError(t, err)
+
+		require.Equal(t, original.Snapshot(), restored.Snapshot())
+		require.Equal(t, original.lastAction, restored.lastAction)
+
+		// Both kitchens discard the same order for the next one and agree on freshness later on
+		next := css.Order{ID: "cold3", Name: "Cold Soda", Temp: string(TemperatureCold), Price: 2, Freshness: 30}
+		for _, k := range []*Kitchen{original, restored} {
+			sink := NewMemorySink()
+			k.sink = sink
+			require.NoError(t, k.PlaceOrder(next))
+			require.Equal(t, []css.Action{
+				{ID: "cold2", Action: css.Discard, Target: css.Shelf},
+				{ID: "cold3", Action: css.Place, Target: css.Shelf},
+			}, withoutTimestamps(sink.Actions()))
+		}
+
+		clock.Advance(10 * time.Second)
+		restoredClock.Advance(10 * time.Second)
+		require.Equal(t, original.Snapshot(), restored.Snapshot())
+	})
+
+	t.Run("WritesVersionedState", func(t *testing.T) {
+		k, _ := newKitchen(t)
+
+		var buf bytes.Buffer
+		require.NoError(t, k.Save(&buf))
+		require.True(t, strings.HasPrefix(buf.String(), "{\n  \"version\": 3,"), buf.String())
+	})
+
+	t.Run("RejectsUnsupportedVersion", func(t *testing.T) {
+		for _, version := range []int{2, 4} {
+			_, err := Load(strings.NewReader(fmt.Sprintf(`{"version": %d}`, version)), NewMemorySink(), NewRealClock())
+			require.EqualError(t, err, fmt.Sprintf("unsupported kitchen state version %d, expected 3", version))
+		}
+	})
+
+	t.Run("RestoresStorageUnits", func(t *testing.T) {
+		units := []StorageUnit{
+			{Name: "drawer", Temperatures: []Temperature{TemperatureHot}, Capacity: 1},
+			{
+				Name:         "counter",
+				Temperatures: []Temperature{TemperatureHot, TemperatureRoom},
+				Capacity:     2,
+				Decay:        map[Temperature]DecayModel{TemperatureHot: ExponentialDecay{Rate: 1, Doubling: time.Minute}},
+				Priority:     1,
+			},
+		}
+		clock := NewFakeClock(start)
+		original, err := NewKitchenWithUnits(units, NewMemorySink(), clock)
+		require.NoError(t, err)
+		require.NoError(t, original.PlaceOrder(orders[0]))
+		require.NoError(t, original.PlaceOrder(orders[4]))
+		require.NoError(t, original.PlaceOrder(orders[3]))
+
+		var buf bytes.Buffer
+		require.NoError(t, original.Save(&buf))
+		restored, err := Load(&buf, NewMemorySink(), clock)
+		require.NoError(t, err)
+
+		clock.Advance(30 * time.Second)
+		require.Equal(t, original.Snapshot(), restored.Snapshot())
+
+		counter, _ := restored.Snapshot().Storage("counter")
+		require.Len(t, counter.Orders, 2)
+	})
+
+	t.Run("RejectsInconsistentState", func(t *testing.T) {
+		load := func(storages string) error {
+			_, err := Load(strings.NewReader(`{"version": 3, "storages": [`+storages+`]}`), NewMemorySink(), NewRealClock())
+			return err
+		}
+		hot := func(id string) string {
+			return `{"id": "` + id + `", "name": "Hot Pizza", "temperature": "hot", "price": 1, "freshness": 1000000000}`
+		}
+
+		err := load(`{"name": "heater", "temperatures": ["hot"], "capacity": 1, "orders": [` + hot("hot1") + `, ` + hot("hot2") + `]}`)
+		require.EqualError(t, err, "heater: more orders than capacity 1")
+
+		err = load(`{"name": "cooler", "temperatures": ["cold"], "capacity": 1, "orders": [` + hot("hot1") + `]}`)
+		require.EqualError(t, err, "cooler: hot order hot1 not accepted")
+
+		err = load(`{"name": "heater", "temperatures": ["hot"], "capacity": 1, "orders": [` + hot("hot1") + `]},
+			{"name": "shelf", "temperatures": ["hot"], "capacity": 1, "shelf": true, "priority": 1, "orders": [` + hot("hot1") + `]}`)
+		require.EqualError(t, err, "shelf: order hot1 already in heater")
+
+		_, err = Load(strings.NewReader("not json"), NewMemorySink(), NewRealClock())
+		require.ErrorContains(t, err, "failed to read kitchen state")
+	})
+}
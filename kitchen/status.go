@@ -0,0 +1,142 @@
+package kitchen
+
+import (
+	"challenge/client"
+	"time"
+)
+
+// defaultHistoryLimit is how many finished orders a kitchen remembers by default.
+const defaultHistoryLimit = 1000
+
+// OrderState is where an order is in its lifecycle.
+type OrderState string
+
+const (
+	OrderPlaced    OrderState = "placed"
+	OrderMoved     OrderState = "moved"
+	OrderExpired   OrderState = "expired" // still stored, but its freshness has run out
+	OrderPickedUp  OrderState = "picked_up"
+	OrderDiscarded OrderState = "discarded"
+)
+
+// Transition is a step in the lifecycle of an order.
+type Transition struct {
+	State  OrderState
+	Target string    // storage the order was placed in, moved to, picked up or discarded from
+	Time   time.Time // timestamp of the action
+}
+
+// OrderStatus is the current state of an order and how it got there.
+type OrderStatus struct {
+	ID        string
+	Order     client.Order // the order as placed
+	State     OrderState
+	Location  string        // storage holding the order, empty once picked up or discarded
+	Freshness time.Duration // remaining freshness of an order still stored
+	History   []Transition
+}
+
+// Finished reports whether the order has left the kitchen.
+func (s OrderStatus) Finished() bool {
+	return s.State == OrderPickedUp || s.State == OrderDiscarded
+}
+
+// WithHistoryLimit sets how many picked up and discarded orders the kitchen remembers for Status.
+// The oldest are forgotten first, and a negative limit is taken as 0. The default is 1000.
+func WithHistoryLimit(limit int) Option {
+	return func(k *Kitchen) {
+		k.historyLimit = max(limit, 0)
+	}
+}
+
+// Status returns the state, location and history of an order still in the kitchen or among the
+// last finished ones. It returns false for an order that was never placed or has been forgotten.
+func (k *Kitchen) Status(orderID string) (OrderStatus, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	record, ok := k.orders[orderID]
+	if !ok {
+		return OrderStatus{}, false
+	}
+
+	status := *record
+	status.History = append([]Transition(nil), record.History...)
+
+	if !status.Finished() {
+		if stored, ok := k.storedOrder(status.Location, orderID); ok {
+			status.Freshness = stored.Freshness
+			if stored.Freshness <= 0 {
+				status.State = OrderExpired
+			}
+		}
+	}
+
+	return status, true
+}
+
+// track records an action in the lifecycle of its order. It must be called with k.mu held.
+func (k *Kitchen) track(action client.Action, order *KitchenOrder) {
+	record, ok := k.orders[action.ID]
+	if !ok || action.Action == client.Place {
+		record = &OrderStatus{ID: action.ID, Order: placedOrder(order)}
+		k.orders[action.ID] = record
+	}
+
+	switch action.Action {
+	case client.Place:
+		record.State, record.Location = OrderPlaced, action.Target
+	case client.Move:
+		record.State, record.Location = OrderMoved, action.Target
+	case client.Pickup:
+		record.State, record.Location = OrderPickedUp, ""
+	case client.Discard:
+		record.State, record.Location = OrderDiscarded, ""
+	}
+
+	record.History = append(record.History, Transition{
+		State:  record.State,
+		Target: action.Target,
+		Time:   time.UnixMicro(action.Timestamp),
+	})
+
+	if record.Finished() {
+		k.finished = append(k.finished, record)
+		if n := len(k.finished) - k.historyLimit; n > 0 {
+			for _, oldest := range k.finished[:n] {
+				// The order may have been placed again since, in which case it has a new record
+				if k.orders[oldest.ID] == oldest {
+					delete(k.orders, oldest.ID)
+				}
+			}
+			// Copy the rest so the forgotten records do not stay reachable from the backing array
+			k.finished = append([]*OrderStatus(nil), k.finished[n:]...)
+		}
+	}
+}
+
+// placedOrder returns the order as it was placed, before any time was spent in storage.
+func placedOrder(order *KitchenOrder) client.Order {
+	return client.Order{
+		ID:        order.ID,
+		Name:      order.Name,
+		Temp:      string(order.Temperature),
+		Price:     order.Price,
+		Freshness: int(order.Freshness / time.Second),
+	}
+}
+
+// storedOrder returns an order in the named storage.
+func (k *Kitchen) storedOrder(location string, orderID string) (StoredOrder, bool) {
+	u, ok := k.unit(location)
+	if !ok {
+		return StoredOrder{}, false
+	}
+
+	for _, item := range u.store.itemsAt(k.clock.Now()) {
+		if item.ID == orderID {
+			return item, true
+		}
+	}
+	return StoredOrder{}, false
+}
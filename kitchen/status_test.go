@@ -0,0 +1,157 @@
+package kitchen
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	css "challenge/client"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKitchen_Status(t *testing.T) {
+	hot1 := css.Order{ID: "hot1", Name: "Hot Pizza", Temp: string(TemperatureHot), Price: 10, Freshness: 60}
+	hot2 := css.Order{ID: "hot2", Name: "Hot Soup", Temp: string(TemperatureHot), Price: 8, Freshness: 70}
+	cold1 := css.Order{ID: "cold1", Name: "Cold Salad", Temp: string(TemperatureCold), Price: 5, Freshness: 90}
+
+	start := time.Unix(1_700_000_000, 0)
+
+	states := func(history []Transition) []OrderState {
+		var s []OrderState
+		for _, transition := range history {
+			s = append(s, transition.State)
+		}
+		return s
+	}
+
+	t.Run("TracksPlaceMoveAndPickup", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		k := NewKitchen(1, 0, 1, 2, NewMemorySink(), clock)
+
+		require.NoError(t, k.PlaceOrder(hot1))
+		require.NoError(t, k.PlaceOrder(hot2))
+
+		status, ok := k.Status("hot2")
+		require.True(t, ok)
+		require.Equal(t, OrderPlaced, status.State)
+		require.Equal(t, css.Shelf, status.Location)
+		require.Equal(t, 70*time.Second, status.Freshness)
+
+		// Picking up hot1 frees the heater, hot2 moves there to make room for cold1
+		clock.Advance(time.Second)
+		_, err := k.PickUpOrder("hot1")
+		require.NoError(t, err)
+		clock.Advance(time.Second)
+		require.NoError(t, k.PlaceOrder(cold1))
+
+		status, ok = k.Status("hot2")
+		require.True(t, ok)
+		require.Equal(t, OrderMoved, status.State)
+		require.Equal(t, css.Heater, status.Location)
+		// hot2 was placed in the same microsecond as hot1, its timestamp is one microsecond later
+		require.Equal(t, []Transition{
+			{State: OrderPlaced, Target: css.Shelf, Time: start},
+			{State: OrderMoved, Target: css.Heater, Time: start.Add(2 * time.Second)},
+		}, status.History)
+
+		status, ok = k.Status("hot1")
+		require.True(t, ok)
+		require.True(t, status.Finished())
+		require.Equal(t, OrderPickedUp, status.State)
+		require.Empty(t, status.Location)
+		require.Equal(t, []OrderState{OrderPlaced, OrderPickedUp}, states(status.History))
+	})
+
+	t.Run("ReportsExpiredThenDiscarded", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		k := NewKitchen(1, 1, 1, 2, NewMemorySink(), clock)
+		require.NoError(t, k.PlaceOrder(hot1))
+		clock.Advance(2 * time.Minute)
+
+		status, _ := k.Status("hot1")
+		require.Equal(t, OrderExpired, status.State)
+		require.Equal(t, css.Heater, status.Location)
+		require.Equal(t, -time.Minute, status.Freshness)
+
+		_, err := k.PickUpOrder("hot1")
+		require.ErrorIs(t, err, ErrOrderExpired)
+
+		status, _ = k.Status("hot1")
+		require.Equal(t, OrderDiscarded, status.State)
+		require.Equal(t, []OrderState{OrderPlaced, OrderDiscarded}, states(status.History))
+	})
+
+	t.Run("UnknownOrder", func(t *testing.T) {
+		k := NewKitchen(1, 1, 1, 2, NewMemorySink(), NewFakeClock(start))
+
+		_, ok := k.Status("missing")
+		require.False(t, ok)
+	})
+
+	t.Run("ForgetsOldestFinishedOrders", func(t *testing.T) {
+		k := NewKitchen(1, 1, 1, 2, NewMemorySink(), NewFakeClock(start), WithHistoryLimit(1))
+		for _, o := range []css.Order{hot1, hot2, cold1} {
+			require.NoError(t, k.PlaceOrder(o))
+		}
+
+		_, err := k.PickUpOrder("hot1")
+		require.NoError(t, err)
+		_, err = k.PickUpOrder("hot2")
+		require.NoError(t, err)
+
+		_, ok := k.Status("hot1")
+		require.False(t, ok)
+		_, ok = k.Status("hot2")
+		require.True(t, ok)
+
+		// Orders still stored are never forgotten
+		_, ok = k.Status("cold1")
+		require.True(t, ok)
+	})
+
+	t.Run("ForgetsFinishedOrders_WhenLimitIsNegative", func(t *testing.T) {
+		k := NewKitchen(1, 1, 1, 2, NewMemorySink(), NewFakeClock(start), WithHistoryLimit(-1))
+		require.NoError(t, k.PlaceOrder(hot1))
+		_, err := k.PickUpOrder("hot1")
+		require.NoError(t, err)
+
+		_, ok := k.Status("hot1")
+		require.False(t, ok)
+	})
+
+	t.Run("StartsOverWhenOrderIsPlacedAgain", func(t *testing.T) {
+		k := NewKitchen(1, 1, 1, 2, NewMemorySink(), NewFakeClock(start), WithHistoryLimit(1))
+		require.NoError(t, k.PlaceOrder(hot1))
+		_, err := k.PickUpOrder("hot1")
+		require.NoError(t, err)
+		require.NoError(t, k.PlaceOrder(hot1))
+
+		status, _ := k.Status("hot1")
+		require.Equal(t, []OrderState{OrderPlaced}, states(status.History))
+
+		// Forgetting the first, finished hot1 keeps the new one
+		require.NoError(t, k.PlaceOrder(hot2))
+		_, err = k.PickUpOrder("hot2")
+		require.NoError(t, err)
+		_, ok := k.Status("hot1")
+		require.True(t, ok)
+	})
+
+	t.Run("RestoredOrdersStartAsPlaced", func(t *testing.T) {
+		clock := NewFakeClock(start.UTC())
+		k := NewKitchen(1, 1, 1, 2, NewMemorySink(), clock)
+		require.NoError(t, k.PlaceOrder(hot1))
+
+		var buf bytes.Buffer
+		require.NoError(t, k.Save(&buf))
+		restored, err := Load(&buf, NewMemorySink(), clock)
+		require.NoError(t, err)
+
+		status, ok := restored.Status("hot1")
+		require.True(t, ok)
+		require.Equal(t, OrderPlaced, status.State)
+		require.Equal(t, css.Heater, status.Location)
+		require.Equal(t, []Transition{{State: OrderPlaced, Target: css.Heater, Time: start.UTC()}}, status.History)
+	})
+}
@@ -2,6 +2,7 @@ package kitchen
 
 import (
 	"container/list"
+	"sort"
 	"sync"
 	"time"
 )
@@ -9,26 +10,58 @@ import (
 type Temperature string
 
 const (
-	TemperatureHot  Temperature = "hot"
-	TemperatureCold Temperature = "cold"
-	TemperatureRoom Temperature = "room"
+	TemperatureHot    Temperature = "hot"
+	TemperatureCold   Temperature = "cold"
+	TemperatureRoom   Temperature = "room"
+	TemperatureFrozen Temperature = "frozen"
 )
 
+// KitchenOrder is an order in the kitchen. Its freshness is kept as a ledger: the freshness spent
+// in the storages it has left, plus what its current storage's decay model has taken since.
 type KitchenOrder struct {
 	ID          string
 	Name        string
 	Temperature Temperature
 	Price       int
-	Freshness   time.Duration
-	cookedAt    time.Time
-	lastUpdated time.Time
+	Freshness   time.Duration // freshness when placed
+	cookedAt    time.Time     // when the order was placed in the kitchen
+	storedAt    time.Time     // when the order was put in its current storage
+	spent       time.Duration // freshness spent in previous storages
 }
 
-func (k *KitchenOrder) getFreshness(refTime time.Time, decayFactor int) time.Duration {
-	freshnessValue := float64(k.Freshness)
-	timeInStorage := float64(refTime.Sub(k.cookedAt))
-	remaining := freshnessValue - (timeInStorage * float64(decayFactor))
-	return time.Duration(remaining)
+// getFreshness returns the remaining freshness at refTime, given the decay model of the current storage.
+func (k *KitchenOrder) getFreshness(refTime time.Time, decay DecayModel) time.Duration {
+	return k.Freshness - k.spent - decay.Spent(refTime.Sub(k.storedAt))
+}
+
+// settle records the freshness spent in the current storage up to refTime, when the order leaves it.
+func (k *KitchenOrder) settle(refTime time.Time, decay DecayModel) {
+	k.spent = k.Freshness - k.getFreshness(refTime, decay)
+	k.storedAt = refTime
+}
+
+// store records the order as put in a storage at refTime. Its first storage is where it was placed.
+func (k *KitchenOrder) store(refTime time.Time) {
+	if k.cookedAt.IsZero() {
+		k.cookedAt = refTime
+	}
+	k.storedAt = refTime
+}
+
+// remaining returns the freshness left when the order last left a storage.
+func (k *KitchenOrder) remaining() time.Duration {
+	return k.Freshness - k.spent
+}
+
+// StoredOrder is a read-only view of an order sitting in a storage.
+type StoredOrder struct {
+	ID          string
+	Name        string
+	Temperature Temperature
+	Price       int
+	PlacedAt    time.Time     // when the order was placed in the kitchen
+	StoredAt    time.Time     // when the order was put in the storage
+	Freshness   time.Duration // remaining freshness at the time of the view
 }
 
 // -- General storage for Cooler and Heater --
@@ -37,34 +70,50 @@ type Storage struct {
 	capacity int64
 	count    int64
 	items    map[string]*KitchenOrder
+	decay    map[Temperature]DecayModel
+	clock    Clock
 	mu       sync.Mutex
 }
 
-func NewStorage(capacity int64) *Storage {
+func NewStorage(capacity int64, clock Clock) *Storage {
 	return &Storage{
 		capacity: capacity,
 		items:    make(map[string]*KitchenOrder, int(capacity)),
+		clock:    clock,
 	}
 }
 
+// Add stores an order. It returns false if the storage is full or already holds the order ID.
 func (s *Storage) Add(order *KitchenOrder) bool {
+	return s.addAt(order, s.clock.Now())
+}
+
+// addAt stores an order as of the given time.
+func (s *Storage) addAt(order *KitchenOrder, at time.Time) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Ensure there is space
+	// Ensure there is space and the order is not stored already
 	if s.count == s.capacity {
 		return false
 	}
+	if _, ok := s.items[order.ID]; ok {
+		return false
+	}
 
-	order.cookedAt = time.Now()
+	order.store(at)
 
-	// Assume every other is unique
 	s.items[order.ID] = order
 	s.count++
 	return true
 }
 
 func (s *Storage) Remove(orderid string) (*KitchenOrder, bool) {
+	return s.removeAt(orderid, s.clock.Now())
+}
+
+// removeAt removes an order as of the given time, settling its freshness then.
+func (s *Storage) removeAt(orderid string, at time.Time) (*KitchenOrder, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -76,21 +125,92 @@ func (s *Storage) Remove(orderid string) (*KitchenOrder, bool) {
 	delete(s.items, orderid)
 	s.count--
 
-	// Update freshness
-	if order.lastUpdated.IsZero() {
-		order.lastUpdated = order.cookedAt
-	}
-	order.Freshness = order.getFreshness(time.Now(), 1)
+	order.settle(at, s.decayOf(order))
+	return order, ok
+}
 
+func (s *Storage) Get(orderid string) (*KitchenOrder, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.items[orderid]
 	return order, ok
 }
 
+// Items returns a read-only view of the storage, ordered by the time orders were placed in it.
+func (s *Storage) Items() []StoredOrder {
+	return s.itemsAt(s.clock.Now())
+}
+
+func (s *Storage) itemsAt(now time.Time) []StoredOrder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]*KitchenOrder, 0, len(s.items))
+	for _, order := range s.items {
+		orders = append(orders, order)
+	}
+	sortOrders(orders)
+
+	items := make([]StoredOrder, len(orders))
+	for i, order := range orders {
+		items[i] = StoredOrder{
+			ID:          order.ID,
+			Name:        order.Name,
+			Temperature: order.Temperature,
+			Price:       order.Price,
+			PlacedAt:    order.cookedAt,
+			StoredAt:    order.storedAt,
+			Freshness:   order.getFreshness(now, s.decayOf(order)),
+		}
+	}
+	return items
+}
+
+// Expired returns the IDs of the orders whose freshness has run out, oldest first.
+func (s *Storage) Expired() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+
+	var expired []*KitchenOrder
+	for _, order := range s.items {
+		if order.getFreshness(now, s.decayOf(order)) <= 0 {
+			expired = append(expired, order)
+		}
+	}
+
+	return sortedIDs(expired)
+}
+
+// decayOf returns the decay model of an order in the storage.
+func (s *Storage) decayOf(order *KitchenOrder) DecayModel {
+	if model, ok := s.decay[order.Temperature]; ok {
+		return model
+	}
+	return normalDecay
+}
+
+func (s *Storage) setDecay(temp Temperature, model DecayModel) {
+	if s.decay == nil {
+		s.decay = make(map[Temperature]DecayModel)
+	}
+	s.decay[temp] = model
+}
+
 func (s *Storage) HasSpace() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	return s.count < s.capacity
 }
 
+func (s *Storage) State() StorageState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return StorageState{Capacity: s.capacity, Count: s.count}
+}
+
 func (s *Storage) Len() int64 {
 	// This is function is intentionally left unsafe
 	return s.count
@@ -99,48 +219,62 @@ func (s *Storage) Len() int64 {
 // -- Shelf storage --
 
 type ShelfStorage struct {
-	capacity  int64
-	count     int64
-	decay     int
-	items     map[string]*list.Element
-	coldItems *list.List
-	hotItems  *list.List
-	roomItems *list.List
-	mu        sync.Mutex
+	capacity    int64
+	count       int64
+	models      map[Temperature]DecayModel
+	items       map[string]*list.Element
+	coldItems   *list.List
+	hotItems    *list.List
+	roomItems   *list.List
+	frozenItems *list.List
+	clock       Clock
+	mu          sync.Mutex
+}
+
+// NewShelfStorage returns a shelf on which hot and cold orders decay decay times faster than normal.
+func NewShelfStorage(capacity int64, decay int, clock Clock) *ShelfStorage {
+	s := newShelfStorage(capacity, clock)
+	shelfDecay := LinearDecay{Rate: float64(decay)}
+	s.setDecay(TemperatureHot, shelfDecay)
+	s.setDecay(TemperatureCold, shelfDecay)
+	return s
 }
 
-func NewShelfStorage(capacity int64, decay int) *ShelfStorage {
+// newShelfStorage returns a shelf on which orders decay at the normal rate until set otherwise.
+func newShelfStorage(capacity int64, clock Clock) *ShelfStorage {
 	return &ShelfStorage{
-		capacity:  capacity,
-		coldItems: list.New(),
-		hotItems:  list.New(),
-		roomItems: list.New(),
-		decay:     decay,
-		items:     make(map[string]*list.Element, capacity),
+		capacity:    capacity,
+		coldItems:   list.New(),
+		hotItems:    list.New(),
+		roomItems:   list.New(),
+		frozenItems: list.New(),
+		items:       make(map[string]*list.Element, capacity),
+		clock:       clock,
 	}
 }
 
+// Add stores an order. It returns false if the shelf is full or already holds the order ID.
 func (s *ShelfStorage) Add(order *KitchenOrder) bool {
+	return s.addAt(order, s.clock.Now())
+}
+
+// addAt stores an order as of the given time.
+func (s *ShelfStorage) addAt(order *KitchenOrder, at time.Time) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Ensure there is space
+	// Ensure there is space and the order is not stored already
 	if s.count == s.capacity {
 		return false
 	}
+	if _, ok := s.items[order.ID]; ok {
+		return false
+	}
 
 	var el *list.Element
-	order.cookedAt = time.Now()
-
-	// Assume order's temperature is any of cold, hot, room
-	switch order.Temperature {
-	case TemperatureCold:
-		el = s.coldItems.PushBack(order)
-	case TemperatureHot:
-		el = s.hotItems.PushBack(order)
-	default:
-		el = s.roomItems.PushBack(order)
-	}
+	order.store(at)
+
+	el = s.list(order.Temperature).PushBack(order)
 
 	s.items[order.ID] = el
 	s.count++
@@ -148,6 +282,11 @@ func (s *ShelfStorage) Add(order *KitchenOrder) bool {
 }
 
 func (s *ShelfStorage) Remove(orderid string) (*KitchenOrder, bool) {
+	return s.removeAt(orderid, s.clock.Now())
+}
+
+// removeAt removes an order as of the given time, settling its freshness then.
+func (s *ShelfStorage) removeAt(orderid string, at time.Time) (*KitchenOrder, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -157,26 +296,25 @@ func (s *ShelfStorage) Remove(orderid string) (*KitchenOrder, bool) {
 	}
 
 	order := el.Value.(*KitchenOrder)
-	switch order.Temperature {
-	case TemperatureCold:
-		s.coldItems.Remove(el)
-	case TemperatureHot:
-		s.hotItems.Remove(el)
-	default:
-		s.roomItems.Remove(el)
-	}
+	s.list(order.Temperature).Remove(el)
 
 	delete(s.items, orderid)
+	s.count--
 
-	decay := s.decay
-	if order.Temperature == TemperatureRoom {
-		decay = 1
-	}
+	order.settle(at, s.decayOf(order))
+	return order, true
+}
 
-	order.Freshness = order.getFreshness(time.Now(), decay)
+func (s *ShelfStorage) Get(orderid string) (*KitchenOrder, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	s.count--
-	return order, true
+	el, ok := s.items[orderid]
+	if !ok {
+		return nil, false
+	}
+
+	return el.Value.(*KitchenOrder), true
 }
 
 func (s *ShelfStorage) GetOrderToDiscard() *KitchenOrder {
@@ -187,39 +325,64 @@ func (s *ShelfStorage) GetOrderToDiscard() *KitchenOrder {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.count == 0 {
+	id, ok := OldestPolicy{}.Choose(s.view(s.clock.Now()))
+	if !ok {
 		return nil
 	}
 
-	// Get the oldest items from each category
-	coldEl := s.coldItems.Front()
-	hotEl := s.hotItems.Front()
-	roomEl := s.roomItems.Front()
+	return s.items[id].Value.(*KitchenOrder)
+}
+
+// Items returns a read-only view of the shelf, ordered by the time orders were placed on it.
+func (s *ShelfStorage) Items() []StoredOrder {
+	return s.itemsAt(s.clock.Now())
+}
 
-	// If there are no cold or hot items, return the oldest room item
-	if coldEl == nil && hotEl == nil {
-		if roomEl != nil {
-			return roomEl.Value.(*KitchenOrder)
+func (s *ShelfStorage) itemsAt(now time.Time) []StoredOrder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.view(now)
+}
+
+func (s *ShelfStorage) view(now time.Time) []StoredOrder {
+	items := make([]StoredOrder, 0, s.count)
+
+	for _, l := range []*list.List{s.coldItems, s.hotItems, s.roomItems, s.frozenItems} {
+		for el := l.Front(); el != nil; el = el.Next() {
+			order := el.Value.(*KitchenOrder)
+			items = append(items, StoredOrder{
+				ID:          order.ID,
+				Name:        order.Name,
+				Temperature: order.Temperature,
+				Price:       order.Price,
+				PlacedAt:    order.cookedAt,
+				StoredAt:    order.storedAt,
+				Freshness:   order.getFreshness(now, s.decayOf(order)),
+			})
 		}
-		return nil
 	}
 
-	// If only one type exists, return it
-	if coldEl == nil {
-		return hotEl.Value.(*KitchenOrder)
-	}
-	if hotEl == nil {
-		return coldEl.Value.(*KitchenOrder)
-	}
+	// Each list is already in placement order, a stable sort merges them
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].StoredAt.Before(items[j].StoredAt)
+	})
 
-	// Both cold and hot items exist; return the oldest
-	coldOrder := coldEl.Value.(*KitchenOrder)
-	hotOrder := hotEl.Value.(*KitchenOrder)
+	return items
+}
+
+// decayOf returns the decay model of an order on the shelf.
+func (s *ShelfStorage) decayOf(order *KitchenOrder) DecayModel {
+	if model, ok := s.models[order.Temperature]; ok {
+		return model
+	}
+	return normalDecay
+}
 
-	if hotOrder.cookedAt.Before(coldOrder.cookedAt) {
-		return hotOrder
+func (s *ShelfStorage) setDecay(temp Temperature, model DecayModel) {
+	if s.models == nil {
+		s.models = make(map[Temperature]DecayModel)
 	}
-	return coldOrder
+	s.models[temp] = model
 }
 
 func (s *ShelfStorage) GetFirstColdOrder() *KitchenOrder {
@@ -258,13 +421,57 @@ func (s *ShelfStorage) GetFirstRoomOrder() *KitchenOrder {
 	return el.Value.(*KitchenOrder)
 }
 
+// Expired returns the IDs of the orders whose freshness has run out, oldest first.
+func (s *ShelfStorage) Expired() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []string
+	for _, item := range s.view(s.clock.Now()) {
+		if item.Freshness <= 0 {
+			ids = append(ids, item.ID)
+		}
+	}
+
+	return ids
+}
+
 func (s *ShelfStorage) HasSpace() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	return s.count < s.capacity
 }
 
+func (s *ShelfStorage) State() StorageState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return StorageState{Capacity: s.capacity, Count: s.count}
+}
+
 func (s *ShelfStorage) Len() int64 {
 	// This is function is intentionally left unsafe
 	return s.count
 }
+
+// -- Helper Functions --
+
+// sortOrders sorts orders by the time they were stored, then by ID.
+func sortOrders(orders []*KitchenOrder) {
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].storedAt.Equal(orders[j].storedAt) {
+			return orders[i].ID < orders[j].ID
+		}
+		return orders[i].storedAt.Before(orders[j].storedAt)
+	})
+}
+
+// sortedIDs returns the IDs of orders sorted by the time they were stored, then by ID.
+func sortedIDs(orders []*KitchenOrder) []string {
+	sortOrders(orders)
+
+	ids := make([]string, len(orders))
+	for i, order := range orders {
+		ids[i] = order.ID
+	}
+	return ids
+}
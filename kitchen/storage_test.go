@@ -32,7 +32,7 @@ func TestStorage_AddRemoveHasSpace(t *testing.T) {
 		Freshness:   1 * time.Minute,
 	}
 
-	s := NewStorage(2)
+	s := NewStorage(2, NewRealClock())
 	require.True(t, s.HasSpace())
 
 	ok := s.Add(order1)
@@ -93,7 +93,7 @@ func TestShellStorage_AddRemoveHasSpace(t *testing.T) {
 		Freshness:   1 * time.Minute,
 	}
 
-	s := NewShelfStorage(3, 2)
+	s := NewShelfStorage(3, 2, NewRealClock())
 	require.True(t, s.HasSpace())
 
 	ok := s.Add(order1)
@@ -150,6 +150,43 @@ func TestShellStorage_AddRemoveHasSpace(t *testing.T) {
 	require.Nil(t, storedOrder4)
 }
 
+func TestStorage_RejectsDuplicateIDs(t *testing.T) {
+	newOrder := func() *KitchenOrder {
+		return &KitchenOrder{
+			ID:          "order1",
+			Name:        "Hot Pizza",
+			Temperature: TemperatureHot,
+			Price:       10,
+			Freshness:   10 * time.Minute,
+		}
+	}
+
+	t.Run("Storage", func(t *testing.T) {
+		s := NewStorage(2, NewRealClock())
+		require.True(t, s.Add(newOrder()))
+		require.False(t, s.Add(newOrder()))
+		require.Equal(t, int64(1), s.Len())
+
+		_, ok := s.Remove("order1")
+		require.True(t, ok)
+		require.Zero(t, s.Len())
+		require.Equal(t, StorageState{Capacity: 2, Count: 0}, s.State())
+	})
+
+	t.Run("ShelfStorage", func(t *testing.T) {
+		s := NewShelfStorage(2, 2, NewRealClock())
+		require.True(t, s.Add(newOrder()))
+		require.False(t, s.Add(newOrder()))
+		require.Equal(t, int64(1), s.Len())
+		require.Equal(t, 1, s.hotItems.Len())
+
+		_, ok := s.Remove("order1")
+		require.True(t, ok)
+		require.Zero(t, s.Len())
+		require.Zero(t, s.hotItems.Len())
+	})
+}
+
 func TestShellStorage_GetOrderToDiscard(t *testing.T) {
 	coldOrder1 := &KitchenOrder{
 		ID:          "coldOrder1",
@@ -203,8 +240,10 @@ func TestShellStorage_GetOrderToDiscard(t *testing.T) {
 	const decay = 2
 
 	t.Run("ReturnsFirstColdItem_WhenColdItems", func(t *testing.T) {
-		s := NewShelfStorage(capacity, decay)
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(capacity, decay, clock)
 		s.Add(coldOrder1)
+		clock.Advance(time.Second)
 		s.Add(coldOrder2)
 
 		actual := s.GetOrderToDiscard()
@@ -216,8 +255,10 @@ func TestShellStorage_GetOrderToDiscard(t *testing.T) {
 	})
 
 	t.Run("ReturnsFirstHotItem_WhenHotItems", func(t *testing.T) {
-		s := NewShelfStorage(capacity, decay)
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(capacity, decay, clock)
 		s.Add(hotOrder2)
+		clock.Advance(time.Second)
 		s.Add(hotOrder1)
 
 		actual := s.GetOrderToDiscard()
@@ -230,8 +271,10 @@ func TestShellStorage_GetOrderToDiscard(t *testing.T) {
 	})
 
 	t.Run("ReturnsFirstShelfItem_WhenShelfItems", func(t *testing.T) {
-		s := NewShelfStorage(capacity, decay)
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(capacity, decay, clock)
 		s.Add(shelfOrder1)
+		clock.Advance(time.Second)
 		s.Add(shelfOrder2)
 
 		actual := s.GetOrderToDiscard()
@@ -243,10 +286,14 @@ func TestShellStorage_GetOrderToDiscard(t *testing.T) {
 	})
 
 	t.Run("ReturnsFirstHotItem_WhenHotItemsAndShelfItems", func(t *testing.T) {
-		s := NewShelfStorage(capacity, decay)
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(capacity, decay, clock)
 		s.Add(shelfOrder1)
+		clock.Advance(time.Second)
 		s.Add(shelfOrder2)
+		clock.Advance(time.Second)
 		s.Add(hotOrder1)
+		clock.Advance(time.Second)
 		s.Add(hotOrder2)
 
 		actual := s.GetOrderToDiscard()
@@ -258,10 +305,14 @@ func TestShellStorage_GetOrderToDiscard(t *testing.T) {
 	})
 
 	t.Run("ReturnsFirstColdItem_WhenColdItemsAndShelfItems", func(t *testing.T) {
-		s := NewShelfStorage(capacity, decay)
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(capacity, decay, clock)
 		s.Add(shelfOrder1)
+		clock.Advance(time.Second)
 		s.Add(shelfOrder2)
+		clock.Advance(time.Second)
 		s.Add(coldOrder1)
+		clock.Advance(time.Second)
 		s.Add(coldOrder2)
 
 		actual := s.GetOrderToDiscard()
@@ -273,10 +324,14 @@ func TestShellStorage_GetOrderToDiscard(t *testing.T) {
 	})
 
 	t.Run("ReturnsFirstHotItem_WhenColdItemsAndHotItems_AndHotItemIsFirst", func(t *testing.T) {
-		s := NewShelfStorage(capacity, decay)
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(capacity, decay, clock)
 		s.Add(hotOrder1)
+		clock.Advance(time.Second)
 		s.Add(coldOrder1)
+		clock.Advance(time.Second)
 		s.Add(hotOrder2)
+		clock.Advance(time.Second)
 		s.Add(coldOrder2)
 
 		actual := s.GetOrderToDiscard()
@@ -288,10 +343,14 @@ func TestShellStorage_GetOrderToDiscard(t *testing.T) {
 	})
 
 	t.Run("ReturnsFirstColdItem_WhenColdItemsAndHotItems_AndColdItemIsFirst", func(t *testing.T) {
-		s := NewShelfStorage(capacity, decay)
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(capacity, decay, clock)
 		s.Add(coldOrder1)
+		clock.Advance(time.Second)
 		s.Add(hotOrder1)
+		clock.Advance(time.Second)
 		s.Add(hotOrder2)
+		clock.Advance(time.Second)
 		s.Add(coldOrder2)
 
 		actual := s.GetOrderToDiscard()
@@ -303,12 +362,18 @@ func TestShellStorage_GetOrderToDiscard(t *testing.T) {
 	})
 
 	t.Run("ReturnsFirstColdItem_WhenAllItemsItems_AndColdItemIsBeforeHotItem", func(t *testing.T) {
-		s := NewShelfStorage(capacity, decay)
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(capacity, decay, clock)
 		s.Add(shelfOrder1)
+		clock.Advance(time.Second)
 		s.Add(shelfOrder2)
+		clock.Advance(time.Second)
 		s.Add(coldOrder1)
+		clock.Advance(time.Second)
 		s.Add(hotOrder1)
+		clock.Advance(time.Second)
 		s.Add(hotOrder2)
+		clock.Advance(time.Second)
 		s.Add(coldOrder2)
 
 		actual := s.GetOrderToDiscard()
@@ -320,12 +385,18 @@ func TestShellStorage_GetOrderToDiscard(t *testing.T) {
 	})
 
 	t.Run("ReturnsFirstHotItem_WhenAllItemsItems_AndHotItemIsBeforeColdItem", func(t *testing.T) {
-		s := NewShelfStorage(capacity, decay)
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(capacity, decay, clock)
 		s.Add(shelfOrder1)
+		clock.Advance(time.Second)
 		s.Add(shelfOrder2)
+		clock.Advance(time.Second)
 		s.Add(hotOrder1)
+		clock.Advance(time.Second)
 		s.Add(coldOrder1)
+		clock.Advance(time.Second)
 		s.Add(hotOrder2)
+		clock.Advance(time.Second)
 		s.Add(coldOrder2)
 
 		actual := s.GetOrderToDiscard()
@@ -336,9 +407,94 @@ func TestShellStorage_GetOrderToDiscard(t *testing.T) {
 		require.Equal(t, 2, s.roomItems.Len())
 	})
 
+	t.Run("ReturnsColdItem_WhenColdAndHotItemsArePlacedAtTheSameTime", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(capacity, decay, clock)
+		s.Add(hotOrder1)
+		s.Add(coldOrder1)
+
+		actual := s.GetOrderToDiscard()
+
+		require.Equal(t, coldOrder1, actual)
+	})
+
 	t.Run("ReturnsNil_WhenEmpty", func(t *testing.T) {
-		s := NewShelfStorage(capacity, decay)
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(capacity, decay, clock)
 		actual := s.GetOrderToDiscard()
 		require.Nil(t, actual)
 	})
 }
+
+func TestStorage_Freshness(t *testing.T) {
+	newOrder := func(id string, temp Temperature) *KitchenOrder {
+		return &KitchenOrder{
+			ID:          id,
+			Name:        "Order " + id,
+			Temperature: temp,
+			Price:       5,
+			Freshness:   10 * time.Minute,
+		}
+	}
+
+	t.Run("Storage_DecaysAtNormalRate", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		s := NewStorage(1, clock)
+		s.Add(newOrder("hot1", TemperatureHot))
+
+		clock.Advance(4 * time.Minute)
+		order, ok := s.Remove("hot1")
+
+		require.True(t, ok)
+		require.Equal(t, 6*time.Minute, order.remaining())
+	})
+
+	t.Run("ShelfStorage_DecaysHotAndColdOrdersAtDecayRate", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(2, 2, clock)
+		s.Add(newOrder("hot1", TemperatureHot))
+		s.Add(newOrder("cold1", TemperatureCold))
+
+		clock.Advance(4 * time.Minute)
+		hot, ok := s.Remove("hot1")
+		require.True(t, ok)
+		require.Equal(t, 2*time.Minute, hot.remaining())
+
+		cold, ok := s.Remove("cold1")
+		require.True(t, ok)
+		require.Equal(t, 2*time.Minute, cold.remaining())
+	})
+
+	t.Run("ShelfStorage_DecaysRoomOrdersAtNormalRate", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		s := NewShelfStorage(1, 2, clock)
+		s.Add(newOrder("room1", TemperatureRoom))
+
+		clock.Advance(4 * time.Minute)
+		order, ok := s.Remove("room1")
+
+		require.True(t, ok)
+		require.Equal(t, 6*time.Minute, order.remaining())
+	})
+
+	t.Run("KeepsFreshnessSpentInPreviousStorages", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		shelf := NewShelfStorage(1, 2, clock)
+		cooler := NewStorage(1, clock)
+		order := newOrder("cold1", TemperatureCold)
+		shelf.Add(order)
+
+		// 1 minute on the shelf at 2x, then 3 minutes in the cooler at 1x
+		clock.Advance(time.Minute)
+		shelf.Remove("cold1")
+		require.Equal(t, 8*time.Minute, order.remaining())
+		cooler.Add(order)
+
+		clock.Advance(3 * time.Minute)
+		require.Equal(t, 5*time.Minute, cooler.Items()[0].Freshness)
+		cooler.Remove("cold1")
+
+		require.Equal(t, 10*time.Minute, order.Freshness)
+		require.Equal(t, 5*time.Minute, order.remaining())
+	})
+}
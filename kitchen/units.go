@@ -0,0 +1,315 @@
+package kitchen
+
+import (
+	"challenge/client"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"time"
+)
+
+// StorageUnit describes a storage of a kitchen created with NewKitchenWithUnits.
+type StorageUnit struct {
+	Name         string        // target of the actions on the storage
+	Temperatures []Temperature // temperatures of the orders the storage accepts
+	Capacity     int64
+
+	// Decay is how orders of each temperature decay in the storage. Orders of a temperature
+	// missing from it decay at the normal rate.
+	Decay map[Temperature]DecayModel
+
+	// Shelf keeps the orders of each temperature in a list, in the order they were stored, like
+	// the overflow shelf of NewKitchen. Other storages keep their orders in a map.
+	Shelf bool
+
+	// Priority orders the storages accepting a temperature. An order goes to the one with the
+	// lowest priority that has space, and the one with the highest priority is its overflow,
+	// where orders are moved out of or discarded to make room. Ties keep the order of the list.
+	Priority int
+}
+
+// DefaultStorageUnits returns a heater, a cooler and a shelf, plus a freezer ahead of them if
+// frozenCapacity is positive. NewKitchen uses them without a freezer.
+//
+// Hot and cold orders decay decay times faster on the shelf. Frozen orders overflow to the cooler
+// before the shelf, and decay decay times faster for every step away from the freezer.
+func DefaultStorageUnits(hotCapacity, coldCapacity, frozenCapacity, shelfCapacity int64, decay int) []StorageUnit {
+	shelfDecay := LinearDecay{Rate: float64(decay)}
+
+	units := []StorageUnit{
+		{Name: client.Heater, Temperatures: []Temperature{TemperatureHot}, Capacity: hotCapacity},
+		{
+			Name:         client.Cooler,
+			Temperatures: []Temperature{TemperatureCold, TemperatureFrozen},
+			Capacity:     coldCapacity,
+			Decay:        map[Temperature]DecayModel{TemperatureFrozen: shelfDecay},
+		},
+		{
+			Name:         client.Shelf,
+			Temperatures: []Temperature{TemperatureHot, TemperatureCold, TemperatureRoom, TemperatureFrozen},
+			Capacity:     shelfCapacity,
+			Decay: map[Temperature]DecayModel{
+				TemperatureHot:    shelfDecay,
+				TemperatureCold:   shelfDecay,
+				TemperatureFrozen: LinearDecay{Rate: float64(decay * decay)},
+			},
+			Shelf:    true,
+			Priority: 1,
+		},
+	}
+
+	if frozenCapacity > 0 {
+		freezer := StorageUnit{Name: client.Freezer, Temperatures: []Temperature{TemperatureFrozen}, Capacity: frozenCapacity}
+		units = append([]StorageUnit{freezer}, units...)
+	}
+	return units
+}
+
+// ReadStorageUnits reads storage units from a JSON array such as
+//
+//	[{"name": "freezer", "temperatures": ["frozen"], "capacity": 4},
+//	 {"name": "shelf", "temperatures": ["frozen", "room"], "capacity": 8, "shelf": true, "priority": 1,
+//	  "decay": {"frozen": {"model": "exponential", "rate": 1, "doubling": 60000000000}}}]
+//
+// Decay models are linear, exponential or step, with their fields in lower case and durations
+// in nanoseconds.
+func ReadStorageUnits(r io.Reader) ([]StorageUnit, error) {
+	var configs []unitConfig
+	if err := json.NewDecoder(r).Decode(&configs); err != nil {
+		return nil, fmt.Errorf("failed to read storage units: %v", err)
+	}
+
+	units := make([]StorageUnit, len(configs))
+	for i, config := range configs {
+		unit, err := config.unit()
+		if err != nil {
+			return nil, fmt.Errorf("storage unit %q: %v", config.Name, err)
+		}
+		units[i] = unit
+	}
+	return units, nil
+}
+
+// validateUnits checks that the units make a kitchen.
+func validateUnits(units []StorageUnit) error {
+	if len(units) == 0 {
+		return errors.New("no storage units")
+	}
+
+	names := make(map[string]bool, len(units))
+	for i, u := range units {
+		switch {
+		case u.Name == "":
+			return fmt.Errorf("storage unit %d has no name", i)
+		case names[u.Name]:
+			return fmt.Errorf("duplicate storage unit %q", u.Name)
+		case len(u.Temperatures) == 0:
+			return fmt.Errorf("storage unit %q accepts no temperature", u.Name)
+		case u.Capacity < 0:
+			return fmt.Errorf("storage unit %q has negative capacity %d", u.Name, u.Capacity)
+		}
+		names[u.Name] = true
+
+		for _, temp := range u.Temperatures {
+			switch temp {
+			case TemperatureHot, TemperatureCold, TemperatureRoom, TemperatureFrozen:
+			default:
+				return fmt.Errorf("storage unit %q accepts unknown temperature %q", u.Name, temp)
+			}
+		}
+		for temp, model := range u.Decay {
+			if err := validateDecay(model); err != nil {
+				return fmt.Errorf("storage unit %q: %s decay: %v", u.Name, temp, err)
+			}
+		}
+	}
+	return nil
+}
+
+// -- Storage units of a kitchen --
+
+// storage is what a kitchen needs from the storage behind a unit: a ShelfStorage for shelf units,
+// a Storage for the others.
+type storage interface {
+	Add(order *KitchenOrder) bool
+	Remove(orderid string) (*KitchenOrder, bool)
+	addAt(order *KitchenOrder, at time.Time) bool
+	removeAt(orderid string, at time.Time) (*KitchenOrder, bool)
+	decayOf(order *KitchenOrder) DecayModel
+	Get(orderid string) (*KitchenOrder, bool)
+	Items() []StoredOrder
+	Expired() []string
+	HasSpace() bool
+	State() StorageState
+	Len() int64
+
+	itemsAt(now time.Time) []StoredOrder
+	orders() []*KitchenOrder
+	put(order *KitchenOrder) error
+	take(orderid string) (*KitchenOrder, bool)
+	setDecay(temp Temperature, model DecayModel)
+}
+
+type unit struct {
+	StorageUnit
+	store storage
+}
+
+// newUnits builds the storages of a kitchen, in priority order.
+func newUnits(units []StorageUnit, clock Clock) []*unit {
+	built := make([]*unit, len(units))
+	for i, config := range units {
+		u := &unit{StorageUnit: config}
+		u.Temperatures = slices.Clone(config.Temperatures)
+		u.Decay = make(map[Temperature]DecayModel, len(config.Decay))
+
+		if u.Shelf {
+			u.store = newShelfStorage(u.Capacity, clock)
+		} else {
+			u.store = NewStorage(u.Capacity, clock)
+		}
+
+		for temp, model := range config.Decay {
+			u.setDecay(temp, model)
+		}
+		built[i] = u
+	}
+
+	sort.SliceStable(built, func(i, j int) bool {
+		return built[i].Priority < built[j].Priority
+	})
+	return built
+}
+
+func (u *unit) accepts(temp Temperature) bool {
+	return slices.Contains(u.Temperatures, temp)
+}
+
+func (u *unit) setDecay(temp Temperature, model DecayModel) {
+	u.Decay[temp] = model
+	u.store.setDecay(temp, model)
+}
+
+// unit returns the named storage unit.
+func (k *Kitchen) unit(name string) (*unit, bool) {
+	for _, u := range k.units {
+		if u.Name == name {
+			return u, true
+		}
+	}
+	return nil, false
+}
+
+// overflow returns the storage unit of last resort for orders of temp.
+func (k *Kitchen) overflow(temp Temperature) (*unit, bool) {
+	for i := len(k.units) - 1; i >= 0; i-- {
+		if k.units[i].accepts(temp) {
+			return k.units[i], true
+		}
+	}
+	return nil, false
+}
+
+// locate returns the storage unit holding an order.
+func (k *Kitchen) locate(orderID string) (*unit, *KitchenOrder, bool) {
+	for _, u := range k.units {
+		if order, ok := u.store.Get(orderID); ok {
+			return u, order, true
+		}
+	}
+	return nil, nil, false
+}
+
+// -- Configuration --
+
+type unitConfig struct {
+	Name         string                     `json:"name"`
+	Temperatures []Temperature              `json:"temperatures"`
+	Capacity     int64                      `json:"capacity"`
+	Shelf        bool                       `json:"shelf,omitempty"`
+	Priority     int                        `json:"priority,omitempty"`
+	Decay        map[Temperature]decayState `json:"decay,omitempty"`
+}
+
+// decayState is a built-in decay model as written in configurations and saved states.
+type decayState struct {
+	Model    string        `json:"model"` // linear, exponential or step
+	Rate     float64       `json:"rate,omitempty"`
+	Doubling time.Duration `json:"doubling,omitempty"`
+	Steps    []DecayStep   `json:"steps,omitempty"`
+}
+
+func (c unitConfig) unit() (StorageUnit, error) {
+	u := StorageUnit{
+		Name:         c.Name,
+		Temperatures: c.Temperatures,
+		Capacity:     c.Capacity,
+		Shelf:        c.Shelf,
+		Priority:     c.Priority,
+	}
+
+	if len(c.Decay) > 0 {
+		u.Decay = make(map[Temperature]DecayModel, len(c.Decay))
+	}
+	for temp, state := range c.Decay {
+		model, err := state.model()
+		if err != nil {
+			return StorageUnit{}, fmt.Errorf("%s decay: %v", temp, err)
+		}
+		u.Decay[temp] = model
+	}
+	return u, nil
+}
+
+// config returns the configuration of a unit. Decay models other than the built-in ones cannot
+// be written and are left out.
+func (u *unit) config() unitConfig {
+	c := unitConfig{
+		Name:         u.Name,
+		Temperatures: u.Temperatures,
+		Capacity:     u.Capacity,
+		Shelf:        u.Shelf,
+		Priority:     u.Priority,
+	}
+
+	for temp, model := range u.Decay {
+		state, ok := saveDecay(model)
+		if !ok {
+			continue
+		}
+		if c.Decay == nil {
+			c.Decay = make(map[Temperature]decayState, len(u.Decay))
+		}
+		c.Decay[temp] = state
+	}
+	return c
+}
+
+func (d decayState) model() (DecayModel, error) {
+	switch d.Model {
+	case "linear":
+		return LinearDecay{Rate: d.Rate}, nil
+	case "exponential":
+		return ExponentialDecay{Rate: d.Rate, Doubling: d.Doubling}, nil
+	case "step":
+		return StepDecay{Steps: d.Steps}, nil
+	default:
+		return nil, fmt.Errorf("unknown decay model %q, must be one of linear, exponential or step", d.Model)
+	}
+}
+
+func saveDecay(model DecayModel) (decayState, bool) {
+	switch m := model.(type) {
+	case LinearDecay:
+		return decayState{Model: "linear", Rate: m.Rate}, true
+	case ExponentialDecay:
+		return decayState{Model: "exponential", Rate: m.Rate, Doubling: m.Doubling}, true
+	case StepDecay:
+		return decayState{Model: "step", Steps: m.Steps}, true
+	default:
+		return decayState{}, false
+	}
+}
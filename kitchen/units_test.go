@@ -0,0 +1,160 @@
+package kitchen
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	css "challenge/client"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKitchen_StorageUnits(t *testing.T) {
+	hot := []Temperature{TemperatureHot}
+	order := func(id string, temp Temperature) css.Order {
+		return css.Order{ID: id, Name: id, Temp: string(temp), Price: 5, Freshness: 600}
+	}
+
+	// A warming drawer takes hot orders when the heater is full, before the shelf
+	units := []StorageUnit{
+		{Name: "shelf", Temperatures: []Temperature{TemperatureHot, TemperatureCold, TemperatureRoom}, Capacity: 1, Shelf: true, Priority: 2},
+		{Name: "heater", Temperatures: hot, Capacity: 1},
+		{Name: "drawer", Temperatures: hot, Capacity: 1, Priority: 1},
+		{Name: "cooler", Temperatures: []Temperature{TemperatureCold}, Capacity: 1},
+	}
+
+	t.Run("FillsStoragesInPriorityOrder", func(t *testing.T) {
+		sink := NewMemorySink()
+		k, err := NewKitchenWithUnits(units, sink, NewFakeClock(time.Now()))
+		require.NoError(t, err)
+
+		for _, o := range []css.Order{order("hot1", TemperatureHot), order("hot2", TemperatureHot), order("hot3", TemperatureHot), order("hot4", TemperatureHot)} {
+			require.NoError(t, k.PlaceOrder(o))
+		}
+
+		require.Equal(t, []css.Action{
+			{ID: "hot1", Action: css.Place, Target: "heater"},
+			{ID: "hot2", Action: css.Place, Target: "drawer"},
+			{ID: "hot3", Action: css.Place, Target: "shelf"},
+			{ID: "hot3", Action: css.Discard, Target: "shelf"},
+			{ID: "hot4", Action: css.Place, Target: "shelf"},
+		}, withoutTimestamps(sink.Actions()))
+
+		var names []string
+		for _, storage := range k.Snapshot().Storages {
+			names = append(names, storage.Name)
+		}
+		require.Equal(t, []string{"heater", "cooler", "drawer", "shelf"}, names)
+	})
+
+	t.Run("MovesFromOverflowToFirstStorageWithSpace", func(t *testing.T) {
+		sink := NewMemorySink()
+		k, err := NewKitchenWithUnits(units, sink, NewFakeClock(time.Now()))
+		require.NoError(t, err)
+
+		for _, o := range []css.Order{order("cold1", TemperatureCold), order("hot1", TemperatureHot), order("hot2", TemperatureHot), order("hot3", TemperatureHot)} {
+			require.NoError(t, k.PlaceOrder(o))
+		}
+		_, err = k.PickUpOrder("hot1")
+		require.NoError(t, err)
+		require.NoError(t, k.PlaceOrder(order("cold2", TemperatureCold)))
+
+		require.Equal(t, []css.Action{
+			{ID: "hot3", Action: css.Move, Target: "heater"},
+			{ID: "cold2", Action: css.Place, Target: "shelf"},
+		}, withoutTimestamps(sink.Actions()[5:]))
+	})
+
+	t.Run("FullWithoutStorageForTemperature", func(t *testing.T) {
+		k, err := NewKitchenWithUnits(units[1:3], NewMemorySink(), NewFakeClock(time.Now()))
+		require.NoError(t, err)
+
+		require.ErrorIs(t, k.PlaceOrder(order("cold1", TemperatureCold)), ErrKitchenFull)
+	})
+
+	t.Run("StorageTypeFollowsConfig", func(t *testing.T) {
+		// A storage taking several temperatures is a shelf only if configured as one
+		k, err := NewKitchenWithUnits([]StorageUnit{
+			{Name: "counter", Temperatures: []Temperature{TemperatureHot, TemperatureCold}, Capacity: 2},
+			{Name: "shelf", Temperatures: []Temperature{TemperatureRoom}, Capacity: 1, Shelf: true},
+		}, NewMemorySink(), NewFakeClock(time.Now()))
+		require.NoError(t, err)
+
+		counter, _ := k.unit("counter")
+		require.IsType(t, &Storage{}, counter.store)
+		shelf, _ := k.unit("shelf")
+		require.IsType(t, &ShelfStorage{}, shelf.store)
+
+		require.NoError(t, k.PlaceOrder(order("hot1", TemperatureHot)))
+		require.NoError(t, k.PlaceOrder(order("cold1", TemperatureCold)))
+		require.Len(t, counter.store.Items(), 2)
+	})
+
+	t.Run("RejectsInvalidUnits", func(t *testing.T) {
+		testCases := []struct {
+			units    []StorageUnit
+			expected string
+		}{
+			{nil, "no storage units"},
+			{[]StorageUnit{{Temperatures: hot}}, "storage unit 0 has no name"},
+			{[]StorageUnit{{Name: "heater", Temperatures: hot}, {Name: "heater", Temperatures: hot}}, `duplicate storage unit "heater"`},
+			{[]StorageUnit{{Name: "heater"}}, `storage unit "heater" accepts no temperature`},
+			{[]StorageUnit{{Name: "heater", Temperatures: hot, Capacity: -1}}, `storage unit "heater" has negative capacity -1`},
+			{[]StorageUnit{{Name: "heater", Temperatures: []Temperature{"warm"}}}, `storage unit "heater" accepts unknown temperature "warm"`},
+			{
+				[]StorageUnit{{Name: "heater", Temperatures: hot, Decay: map[Temperature]DecayModel{TemperatureHot: LinearDecay{Rate: -1}}}},
+				`storage unit "heater": hot decay: negative rate -1`,
+			},
+			{
+				[]StorageUnit{{Name: "heater", Temperatures: hot, Decay: map[Temperature]DecayModel{TemperatureHot: ExponentialDecay{Rate: 1, Doubling: -time.Second}}}},
+				`storage unit "heater": hot decay: negative doubling time -1s`,
+			},
+			{
+				[]StorageUnit{{Name: "heater", Temperatures: hot, Decay: map[Temperature]DecayModel{TemperatureHot: StepDecay{Steps: []DecayStep{{After: time.Minute, Rate: 1}, {After: 0, Rate: 2}}}}}},
+				`storage unit "heater": hot decay: step 1 at 0s comes before step 0 at 1m0s`,
+			},
+		}
+
+		for _, tc := range testCases {
+			_, err := NewKitchenWithUnits(tc.units, NewMemorySink(), NewRealClock())
+			require.EqualError(t, err, tc.expected)
+		}
+	})
+}
+
+func TestReadStorageUnits(t *testing.T) {
+	t.Run("ReadsUnitsAndDecayModels", func(t *testing.T) {
+		units, err := ReadStorageUnits(strings.NewReader(`[
+			{"name": "heater", "temperatures": ["hot"], "capacity": 2},
+			{"name": "shelf", "temperatures": ["hot", "room"], "capacity": 4, "shelf": true, "priority": 1, "decay": {
+				"hot": {"model": "step", "steps": [{"after": 0, "rate": 1}, {"after": 60000000000, "rate": 3}]},
+				"room": {"model": "linear", "rate": 0.5}
+			}}
+		]`))
+		require.NoError(t, err)
+
+		require.Equal(t, []StorageUnit{
+			{Name: "heater", Temperatures: []Temperature{TemperatureHot}, Capacity: 2},
+			{
+				Name:         "shelf",
+				Temperatures: []Temperature{TemperatureHot, TemperatureRoom},
+				Capacity:     4,
+				Shelf:        true,
+				Priority:     1,
+				Decay: map[Temperature]DecayModel{
+					TemperatureHot:  StepDecay{Steps: []DecayStep{{After: 0, Rate: 1}, {After: time.Minute, Rate: 3}}},
+					TemperatureRoom: LinearDecay{Rate: 0.5},
+				},
+			},
+		}, units)
+	})
+
+	t.Run("RejectsUnknownDecayModel", func(t *testing.T) {
+		_, err := ReadStorageUnits(strings.NewReader(`[{"name": "shelf", "decay": {"hot": {"model": "random"}}}]`))
+		require.EqualError(t, err, `storage unit "shelf": hot decay: unknown decay model "random", must be one of linear, exponential or step`)
+
+		_, err = ReadStorageUnits(strings.NewReader("not json"))
+		require.ErrorContains(t, err, "failed to read storage units")
+	})
+}
@@ -30,10 +30,10 @@ func IsValidOrder(order client.Order) error {
 	// Validate Temperature
 	temp := Temperature(order.Temp)
 	switch temp {
-	case TemperatureHot, TemperatureCold, TemperatureRoom:
+	case TemperatureHot, TemperatureCold, TemperatureRoom, TemperatureFrozen:
 	default:
 		errs = append(
-			errs, ValidationError{Field: "Temp", Message: "must be one of hot, cold, or room"})
+			errs, ValidationError{Field: "Temp", Message: "must be one of hot, cold, room, or frozen"})
 	}
 
 	if order.Price <= 0 {
@@ -1,20 +1,22 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"log/slog"
 	"math/rand/v2"
+	"os"
 	"strings"
 	"sync"
 	"time"
 
 	css "challenge/client"
 	kitchen "challenge/kitchen"
+	"challenge/simulation"
+	"challenge/validate"
 )
 
 var (
@@ -27,67 +29,121 @@ var (
 	min  = flag.Duration("min", 4*time.Second, "Minimum pickup time")
 	max  = flag.Duration("max", 8*time.Second, "Maximum pickup time")
 
-	coolerCapacity = flag.Int64("cooler", 6, "Cooler capacity")
-	heaterCapacity = flag.Int64("heater", 6, "Heater capacity")
-	shelfCapacity  = flag.Int64("shelf", 12, "Shelf capacity")
+	coolerCapacity  = flag.Int64("cooler", 6, "Cooler capacity")
+	heaterCapacity  = flag.Int64("heater", 6, "Heater capacity")
+	freezerCapacity = flag.Int64("freezer", 0, "Freezer capacity (no freezer if zero, frozen orders go to the cooler)")
+	shelfCapacity   = flag.Int64("shelf", 12, "Shelf capacity")
 
-	decayFactor = flag.Int("decay", 2, "Shelf decay multiplier")
-)
-
-func parseLogsToActions(buf *bytes.Buffer) ([]css.Action, error) {
-	var actions []css.Action
-	scanner := bufio.NewScanner(buf)
-
-	for scanner.Scan() {
-		var raw map[string]any
-		if err := json.Unmarshal(scanner.Bytes(), &raw); err != nil {
-			return nil, err
-		}
-
-		var ts int64
-		if t, ok := raw["time"].(string); ok {
-			parsed, err := time.Parse(time.RFC3339Nano, t)
-			if err != nil {
-				return nil, err
-			}
-			ts = parsed.UnixMicro()
-		}
+	decayFactor   = flag.Int("decay", 2, "Shelf decay multiplier")
+	reapInterval  = flag.Duration("reap", 0, "Interval at which expired orders are discarded (disabled if zero)")
+	discardPolicy = flag.String("discard-policy", kitchen.DiscardOldest, "Shelf discard policy: oldest, freshness, price or value")
+	placement     = flag.String("placement", kitchen.PlacementDefault, "Placement strategy: default, freshness or no-move")
 
-		action := css.Action{
-			Timestamp: ts,
-			ID:        fmt.Sprint(raw["order id"]),
-			Action:    fmt.Sprint(raw["msg"]),
-			Target:    fmt.Sprint(raw["target"]),
-		}
+	ordersFile = flag.String("orders", "", "Read orders from a JSON or JSONL file ('-' for stdin) instead of fetching and submitting a problem")
+	ledgerFile = flag.String("ledger", "", "Also write every action to this JSONL file as it happens")
+	logActions = flag.Bool("log-actions", false, "Log every action the kitchen performs")
+	simulate   = flag.Bool("simulate", false, "Replay the problem on a virtual clock and print the ledger instead of submitting")
+)
 
-		actions = append(actions, action)
+func readOrders(path string) ([]css.Order, error) {
+	if path == "-" {
+		return css.ReadOrders(os.Stdin)
 	}
 
-	if err := scanner.Err(); err != nil {
+	f, err := os.Open(path)
+	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
 
-	return actions, nil
+	return css.ReadOrders(f)
 }
 
 func main() {
 	flag.Parse()
 
-	client := css.NewClient(*endpoint, *auth)
-	id, orders, err := client.New(*name, *seed)
+	policy, err := kitchen.DiscardPolicyByName(*discardPolicy)
+	if err != nil {
+		log.Fatalf("Invalid flags: %v", err)
+	}
+	strategy, err := kitchen.PlacementStrategyByName(*placement)
 	if err != nil {
-		log.Fatalf("Failed to fetch test problem: %v", err)
+		log.Fatalf("Invalid flags: %v", err)
+	}
+	options := []kitchen.Option{
+		kitchen.WithDiscardPolicy(policy),
+		kitchen.WithPlacementStrategy(strategy),
+	}
+	if *logActions {
+		options = append(options, kitchen.WithLogger(slog.Default()))
+	}
+
+	client := css.NewClient(*endpoint, *auth)
+
+	var id string
+	var orders []css.Order
+	if *ordersFile != "" {
+		orders, err = readOrders(*ordersFile)
+		if err != nil {
+			log.Fatalf("Failed to read orders: %v", err)
+		}
+	} else {
+		id, orders, err = client.New(*name, *seed)
+		if err != nil {
+			log.Fatalf("Failed to fetch test problem: %v", err)
+		}
+	}
+
+	if *simulate {
+		var rnd *rand.Rand
+		if *seed != 0 {
+			rnd = rand.New(rand.NewPCG(uint64(*seed), 0))
+		}
+
+		actions, err := simulation.Run(orders, simulation.Config{
+			FreezerCapacity: *freezerCapacity,
+			HeaterCapacity:  *heaterCapacity,
+			CoolerCapacity:  *coolerCapacity,
+			ShelfCapacity:   *shelfCapacity,
+			Decay:           *decayFactor,
+			Rate:            *rate,
+			Min:             *min,
+			Max:             *max,
+			ReapInterval:    *reapInterval,
+			Rand:            rnd,
+			Options:         options,
+		})
+		if err != nil {
+			log.Fatalf("Invalid flags: %v", err)
+		}
+		printLogs(actions)
+		fmt.Println(validateActions(orders, actions))
+		return
 	}
 
 	// ------ Execution harness logic goes here using rate, min and max ------
-	var buf bytes.Buffer
-	kitchen := kitchen.NewKitchen(
-		*heaterCapacity,
-		*coolerCapacity,
-		*shelfCapacity,
-		*decayFactor,
-		slog.New(slog.NewJSONHandler(&buf, nil)),
-	)
+	ledger := kitchen.NewMemorySink()
+	sinks := kitchen.MultiSink{ledger}
+	if *ledgerFile != "" {
+		f, err := os.Create(*ledgerFile)
+		if err != nil {
+			log.Fatalf("Failed to create ledger file: %v", err)
+		}
+		defer f.Close()
+		sinks = append(sinks, kitchen.NewJSONLSink(f))
+	}
+
+	units := kitchen.DefaultStorageUnits(*heaterCapacity, *coolerCapacity, *freezerCapacity, *shelfCapacity, *decayFactor)
+	kitchen, err := kitchen.NewKitchenWithUnits(units, sinks, kitchen.NewRealClock(), options...)
+	if err != nil {
+		log.Fatalf("Invalid flags: %v", err)
+	}
+
+	ctx, stopReaper := context.WithCancel(context.Background())
+	defer stopReaper()
+	if *reapInterval > 0 {
+		go kitchen.RunReaper(ctx, *reapInterval)
+	}
 
 	ticker := time.NewTicker(*rate)
 	defer ticker.Stop()
@@ -100,7 +156,10 @@ func main() {
 
 		log.Printf("Received: %+v", order)
 
-		kitchen.PlaceOrder(order)
+		if err := kitchen.PlaceOrder(order); err != nil {
+			logPlaceError(order, err)
+			continue
+		}
 		wg.Add(1)
 		go func(o css.Order) {
 			defer wg.Done()
@@ -108,20 +167,27 @@ func main() {
 			randomDelay := *min + rand.N(*max-*min)
 			time.Sleep(randomDelay)
 
-			kitchen.PickUpOrder(o.ID)
+			if _, err := kitchen.PickUpOrder(o.ID); err != nil {
+				logPickupError(o, err)
+			}
 		}(order)
 	}
 
 	wg.Wait()
+	stopReaper()
 
-	actions, err := parseLogsToActions(&buf)
-	if err != nil {
-		print(err)
-		log.Fatalf("Failed to parse logs: %v", err)
-	}
+	actions := ledger.Actions()
 
 	// ------------------------------------------------------------------------
 
+	report := validateActions(orders, actions)
+	if *ordersFile != "" {
+		printLogs(actions)
+		fmt.Println(report)
+		return
+	}
+	log.Printf("Local validation: %v", report)
+
 	result, err := client.Solve(id, *rate, *min, *max, actions)
 	if err != nil {
 		log.Fatalf("Failed to submit test solution: %v", err)
@@ -129,6 +195,53 @@ func main() {
 	log.Printf("Test result: %v", result)
 }
 
+func logPlaceError(order css.Order, err error) {
+	var invalid kitchen.ValidationErrors
+	switch {
+	case errors.As(err, &invalid):
+		log.Printf("Rejected invalid order %v: %v", order.ID, invalid)
+	case errors.Is(err, kitchen.ErrDuplicateOrder):
+		log.Printf("Rejected order %v, its ID is taken by a different order", order.ID)
+	case errors.Is(err, kitchen.ErrKitchenFull):
+		log.Printf("No room for order %v, it was not placed", order.ID)
+	default:
+		log.Fatalf("Failed to place order %v: %v", order.ID, err)
+	}
+}
+
+func logPickupError(order css.Order, err error) {
+	var expired *kitchen.ExpiredOrderError
+	switch {
+	case errors.As(err, &expired):
+		log.Printf("Discarded expired order %v at pickup (freshness %v)", order.ID, expired.Freshness)
+	case errors.Is(err, kitchen.ErrOrderNotFound):
+		// The order was discarded to make room or by the reaper
+		log.Printf("Order %v was gone at pickup", order.ID)
+	default:
+		log.Fatalf("Failed to pick up order %v: %v", order.ID, err)
+	}
+}
+
+func validateActions(orders []css.Order, actions []css.Action) validate.Report {
+	options := css.Options{
+		Rate: rate.Microseconds(),
+		Min:  min.Microseconds(),
+		Max:  max.Microseconds(),
+	}
+
+	return validate.Validate(orders, options, actions, validate.Config{
+		FreezerCapacity: *freezerCapacity,
+		HeaterCapacity:  *heaterCapacity,
+		CoolerCapacity:  *coolerCapacity,
+		ShelfCapacity:   *shelfCapacity,
+		Decay:           *decayFactor,
+		Tolerance:       50 * time.Millisecond,
+
+		// Only the default policy follows the README discard criteria
+		IgnoreDiscardOrder: *discardPolicy != kitchen.DiscardOldest,
+	})
+}
+
 func printLogs(actions []css.Action) {
 	fmt.Printf("%-20s | %-10s | %-10s | %-10s\n", "TIMESTAMP", "ACTION", "ORDER ID", "TARGET")
 	fmt.Println(strings.Repeat("-", 60))
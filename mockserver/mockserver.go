@@ -0,0 +1,166 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+
+	css "challenge/client"
+	"challenge/validate"
+)
+
+const (
+	newPath   = "/interview/challenge/new"
+	solvePath = "/interview/challenge/solve"
+)
+
+type dish struct {
+	name string
+	temp string
+}
+
+var menu = []dish{
+	{"Cheese Pizza", "hot"},
+	{"Beef Burrito", "hot"},
+	{"Chicken Ramen", "hot"},
+	{"Tomato Soup", "hot"},
+	{"Garlic Bread", "hot"},
+	{"Caesar Salad", "cold"},
+	{"Sushi Platter", "cold"},
+	{"Yogurt Parfait", "cold"},
+	{"Iced Latte", "cold"},
+	{"Poke Bowl", "cold"},
+	{"Turkey Sandwich", "room"},
+	{"Blueberry Muffin", "room"},
+	{"Bag of Chips", "room"},
+	{"Banana", "room"},
+	{"Granola Bar", "room"},
+}
+
+// Server is a local stand-in for the challenge problem server. It serves the same endpoints
+// client.Client calls, generates reproducible problems from a seed and grades submitted solutions.
+type Server struct {
+	auth       string
+	orderCount int
+	cfg        validate.Config
+	problems   map[string][]css.Order
+	nextID     int
+	mu         sync.Mutex
+}
+
+// NewServer returns a server generating problems of orderCount orders and grading solutions
+// against a kitchen described by cfg. When auth is not empty, requests must carry the same token.
+func NewServer(auth string, orderCount int, cfg validate.Config) *Server {
+	return &Server{
+		auth:       auth,
+		orderCount: orderCount,
+		cfg:        cfg,
+		problems:   make(map[string][]css.Order),
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.auth != "" && r.URL.Query().Get("auth") != s.auth {
+		http.Error(w, "invalid auth token", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case newPath:
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleNew(w, r)
+	case solvePath:
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleSolve(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) handleNew(w http.ResponseWriter, r *http.Request) {
+	var seed int64
+	if v := r.URL.Query().Get("seed"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid seed %q", v), http.StatusBadRequest)
+			return
+		}
+		seed = parsed
+	}
+	if seed == 0 {
+		seed = rand.Int64()
+	}
+
+	orders := GenerateOrders(seed, s.orderCount)
+
+	s.mu.Lock()
+	s.nextID++
+	id := fmt.Sprintf("%d-%d", seed, s.nextID)
+	s.problems[id] = orders
+	s.mu.Unlock()
+
+	log.Printf("Generated test problem, id=%v, name=%q, orders=%d", id, r.URL.Query().Get("name"), len(orders))
+
+	w.Header().Set("x-test-id", id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+func (s *Server) handleSolve(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get("x-test-id")
+
+	s.mu.Lock()
+	orders, ok := s.problems[id]
+	s.mu.Unlock()
+
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown test id %q", id), http.StatusNotFound)
+		return
+	}
+
+	var solution css.Solution
+	if err := json.NewDecoder(r.Body).Decode(&solution); err != nil {
+		http.Error(w, fmt.Sprintf("invalid solution: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	report := validate.Validate(orders, solution.Options, solution.Actions, s.cfg)
+
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprint(w, report.String())
+}
+
+// GenerateOrders returns count orders derived from seed. The same seed always yields the same orders.
+func GenerateOrders(seed int64, count int) []css.Order {
+	rnd := rand.New(rand.NewPCG(uint64(seed), uint64(seed)>>32))
+
+	orders := make([]css.Order, 0, count)
+	seen := make(map[string]bool, count)
+	for len(orders) < count {
+		id := strconv.FormatUint(rnd.Uint64()%(1<<30), 36)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		d := menu[rnd.IntN(len(menu))]
+		orders = append(orders, css.Order{
+			ID:        id,
+			Name:      d.name,
+			Temp:      d.temp,
+			Price:     1 + rnd.IntN(30),
+			Freshness: 30 + rnd.IntN(271),
+		})
+	}
+	return orders
+}
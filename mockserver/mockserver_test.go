@@ -0,0 +1,95 @@
+package mockserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	css "challenge/client"
+	"challenge/simulation"
+	"challenge/validate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateOrders(t *testing.T) {
+	t.Run("IsReproducibleWithSameSeed", func(t *testing.T) {
+		require.Equal(t, GenerateOrders(42, 20), GenerateOrders(42, 20))
+		require.NotEqual(t, GenerateOrders(42, 20), GenerateOrders(43, 20))
+	})
+
+	t.Run("GeneratesValidUniqueOrders", func(t *testing.T) {
+		orders := GenerateOrders(7, 100)
+		require.Len(t, orders, 100)
+
+		seen := map[string]bool{}
+		for _, o := range orders {
+			require.False(t, seen[o.ID], "duplicate id %s", o.ID)
+			seen[o.ID] = true
+			require.Contains(t, []string{"hot", "cold", "room"}, o.Temp)
+			require.Positive(t, o.Price)
+			require.Positive(t, o.Freshness)
+		}
+	})
+}
+
+func TestServer(t *testing.T) {
+	ts := httptest.NewServer(NewServer("secret", 10, validate.Config{
+		HeaterCapacity: 6,
+		CoolerCapacity: 6,
+		ShelfCapacity:  12,
+		Decay:          2,
+	}))
+	defer ts.Close()
+
+	rate, min, max := 500*time.Millisecond, 4*time.Second, 8*time.Second
+	client := css.NewClient(ts.URL, "secret")
+
+	t.Run("PassesSimulatedSolution", func(t *testing.T) {
+		id, orders, err := client.New("", 1234)
+		require.NoError(t, err)
+		require.NotEmpty(t, id)
+		require.Equal(t, GenerateOrders(1234, 10), orders)
+
+		actions, err := simulation.Run(orders, simulation.Config{
+			HeaterCapacity: 6,
+			CoolerCapacity: 6,
+			ShelfCapacity:  12,
+			Decay:          2,
+			Rate:           rate,
+			Min:            min,
+			Max:            max,
+		})
+		require.NoError(t, err)
+
+		result, err := client.Solve(id, rate, min, max, actions)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(result, "pass"), result)
+	})
+
+	t.Run("FailsIncompleteSolution", func(t *testing.T) {
+		id, orders, err := client.New("", 99)
+		require.NoError(t, err)
+
+		actions := []css.Action{{Timestamp: 1, ID: orders[0].ID, Action: css.Place, Target: css.Shelf}}
+
+		result, err := client.Solve(id, rate, min, max, actions)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(result, "fail"), result)
+		require.Contains(t, result, "never picked up or discarded")
+	})
+
+	t.Run("RejectsUnknownTestID", func(t *testing.T) {
+		_, err := client.Solve("nope", rate, min, max, nil)
+		require.ErrorContains(t, err, "404")
+	})
+
+	t.Run("RejectsInvalidAuth", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + newPath + "?auth=wrong")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	css "challenge/client"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment, so proxies keep it open.
+const keepAliveInterval = 15 * time.Second
+
+// filter selects the actions sent to a subscriber. Empty sets match everything.
+type filter struct {
+	ids     map[string]bool
+	targets map[string]bool
+}
+
+// newFilter reads the filter from the id and target query parameters, which may be repeated.
+func newFilter(r *http.Request) filter {
+	query := r.URL.Query()
+	return filter{
+		ids:     toSet(query["id"]),
+		targets: toSet(query["target"]),
+	}
+}
+
+func (f filter) match(action css.Action) bool {
+	if len(f.ids) > 0 && !f.ids[action.ID] {
+		return false
+	}
+	if len(f.targets) > 0 && !f.targets[action.Target] {
+		return false
+	}
+	return true
+}
+
+// handleEvents streams actions as Server-Sent Events, one JSON action per event.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	f := newFilter(r)
+
+	actions, unsubscribe := s.events.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case action, ok := <-actions:
+			if !ok {
+				return
+			}
+			if !f.match(action) {
+				continue
+			}
+
+			data, _ := json.Marshal(action)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", action.Action, data)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// handleWebSocket streams actions over a WebSocket, one JSON action per text message.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	f := newFilter(r)
+
+	key, err := websocketKey(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, Error{Error: err.Error()})
+		return
+	}
+
+	// Subscribe before the handshake completes, so no action is missed once the client is connected
+	actions, unsubscribe := s.events.Subscribe()
+	defer unsubscribe()
+
+	conn, err := upgradeWebSocket(w, key)
+	if err != nil {
+		// The connection is only left to answer if it could not be taken over
+		if errors.Is(err, http.ErrNotSupported) {
+			writeJSON(w, http.StatusInternalServerError, Error{Error: err.Error()})
+		}
+		return
+	}
+	defer conn.Close()
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.readLoop()
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case action, ok := <-actions:
+			if !ok {
+				return
+			}
+			if !f.match(action) {
+				continue
+			}
+
+			data, _ := json.Marshal(action)
+			if err := conn.WriteText(data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
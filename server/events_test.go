@@ -0,0 +1,141 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	css "challenge/client"
+	"challenge/kitchen"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Events(t *testing.T) {
+	hot := css.Order{ID: "hot1", Name: "Hot Pizza", Temp: "hot", Price: 10, Freshness: 60}
+	cold := css.Order{ID: "cold1", Name: "Cold Salad", Temp: "cold", Price: 5, Freshness: 60}
+
+	newServer := func(t *testing.T) (*httptest.Server, *kitchen.Kitchen, *kitchen.BroadcastSink) {
+		events := kitchen.NewBroadcastSink(16)
+		k := kitchen.NewKitchen(1, 1, 2, 2, events, kitchen.NewFakeClock(time.Unix(1_700_000_000, 0)))
+		ts := httptest.NewServer(NewServer(k, events, NewMetrics()))
+		t.Cleanup(ts.Close)
+		return ts, k, events
+	}
+
+	t.Run("SSE_StreamsFilteredActions", func(t *testing.T) {
+		ts, k, events := newServer(t)
+
+		resp, err := http.Get(ts.URL + "/events?target=" + css.Cooler)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		require.NoError(t, k.PlaceOrder(hot))
+		require.NoError(t, k.PlaceOrder(cold))
+		_, err = k.PickUpOrder(cold.ID)
+		require.NoError(t, err)
+		events.Close()
+
+		var actions []css.Action
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				var action css.Action
+				require.NoError(t, json.Unmarshal([]byte(data), &action))
+				actions = append(actions, action)
+			}
+		}
+
+		require.Equal(t, []css.Action{
+			{ID: "cold1", Action: css.Place, Target: css.Cooler},
+			{ID: "cold1", Action: css.Pickup, Target: css.Cooler},
+		}, withoutTimestamps(actions))
+	})
+
+	t.Run("WebSocket_StreamsFilteredActions", func(t *testing.T) {
+		ts, k, _ := newServer(t)
+
+		conn, err := net.Dial("tcp", strings.TrimPrefix(ts.URL, "http://"))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = conn.Write([]byte("GET /events/ws?id=hot1 HTTP/1.1\r\n" +
+			"Host: kitchen\r\n" +
+			"Upgrade: websocket\r\n" +
+			"Connection: Upgrade\r\n" +
+			"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+			"Sec-WebSocket-Version: 13\r\n\r\n"))
+		require.NoError(t, err)
+
+		r := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(r, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+		require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+		require.NoError(t, k.PlaceOrder(cold))
+		require.NoError(t, k.PlaceOrder(hot))
+
+		ws := &websocketConn{conn: conn, rw: bufio.NewReadWriter(r, bufio.NewWriter(conn))}
+		op, payload, err := ws.readFrame()
+		require.NoError(t, err)
+		require.Equal(t, byte(opText), op)
+
+		var action css.Action
+		require.NoError(t, json.Unmarshal(payload, &action))
+		require.Equal(t, "hot1", action.ID)
+		require.Equal(t, css.Place, action.Action)
+
+		// Pings are answered, a close ends the stream
+		require.NoError(t, ws.writeFrame(opPing, []byte("ping")))
+		op, payload, err = ws.readFrame()
+		require.NoError(t, err)
+		require.Equal(t, byte(opPong), op)
+		require.Equal(t, "ping", string(payload))
+
+		require.NoError(t, ws.writeFrame(opClose, nil))
+		op, _, err = ws.readFrame()
+		require.NoError(t, err)
+		require.Equal(t, byte(opClose), op)
+	})
+
+	t.Run("WebSocket_RejectsPlainRequest", func(t *testing.T) {
+		ts, _, _ := newServer(t)
+
+		resp, err := http.Get(ts.URL + "/events/ws")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("WebSocket_Returns500_WhenConnectionCannotBeTakenOver", func(t *testing.T) {
+		events := kitchen.NewBroadcastSink(16)
+		k := kitchen.NewKitchen(1, 1, 2, 2, events, kitchen.NewFakeClock(time.Unix(1_700_000_000, 0)))
+
+		req := httptest.NewRequest(http.MethodGet, "/events/ws", nil)
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+
+		// A recorder cannot be hijacked, so the handshake fails before the connection is taken over
+		rec := httptest.NewRecorder()
+		NewServer(k, events, NewMetrics()).ServeHTTP(rec, req)
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func withoutTimestamps(actions []css.Action) []css.Action {
+	stripped := make([]css.Action, len(actions))
+	for i, a := range actions {
+		a.Timestamp = 0
+		stripped[i] = a
+	}
+	return stripped
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	css "challenge/client"
+	"challenge/kitchen"
+)
+
+var (
+	// freshnessBuckets are the upper bounds of the pickup freshness histogram, in seconds.
+	freshnessBuckets = []float64{0, 10, 30, 60, 120, 180, 300}
+
+	// latencyBuckets are the upper bounds of the operation latency histogram, in seconds.
+	latencyBuckets = []float64{0.00001, 0.00005, 0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1}
+)
+
+// Metrics collects the kitchen metrics exposed on /metrics in the Prometheus text format. It is an
+// ActionSink counting every action, so it must receive the actions of the kitchen.
+type Metrics struct {
+	actions   map[string]uint64
+	freshness *histogram
+	latency   map[string]*histogram // by operation, place or pickup
+	mu        sync.Mutex
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		actions:   make(map[string]uint64),
+		freshness: newHistogram(freshnessBuckets),
+		latency: map[string]*histogram{
+			css.Place:  newHistogram(latencyBuckets),
+			css.Pickup: newHistogram(latencyBuckets),
+		},
+	}
+}
+
+func (m *Metrics) Emit(action css.Action) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.actions[action.Action]++
+}
+
+// observeLatency records how long an operation, place or pickup, took.
+func (m *Metrics) observeLatency(operation string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latency[operation].observe(d.Seconds())
+}
+
+// observeFreshness records the remaining freshness of an order when it was picked up. It is
+// negative for expired orders.
+func (m *Metrics) observeFreshness(freshness time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.freshness.observe(freshness.Seconds())
+}
+
+// write writes every metric in the Prometheus text format, with storage gauges from the snapshot.
+func (m *Metrics) write(w io.Writer, snapshot kitchen.Snapshot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	header(w, "kitchen_storage_capacity", "gauge", "Number of orders a storage can hold.")
+	for _, storage := range snapshot.Storages {
+		fmt.Fprintf(w, "kitchen_storage_capacity{storage=%q} %d\n", storage.Name, storage.Capacity)
+	}
+
+	header(w, "kitchen_storage_occupancy", "gauge", "Number of orders in a storage.")
+	for _, storage := range snapshot.Storages {
+		fmt.Fprintf(w, "kitchen_storage_occupancy{storage=%q} %d\n", storage.Name, storage.Occupancy)
+	}
+
+	header(w, "kitchen_actions_total", "counter", "Number of actions performed by the kitchen.")
+	for _, action := range []string{css.Place, css.Move, css.Pickup, css.Discard} {
+		fmt.Fprintf(w, "kitchen_actions_total{action=%q} %d\n", action, m.actions[action])
+	}
+
+	header(w, "kitchen_pickup_freshness_seconds", "histogram", "Remaining freshness of orders when picked up.")
+	m.freshness.write(w, "kitchen_pickup_freshness_seconds", "")
+
+	header(w, "kitchen_operation_duration_seconds", "histogram", "Latency of placing and picking up orders.")
+	for _, operation := range []string{css.Place, css.Pickup} {
+		m.latency[operation].write(w, "kitchen_operation_duration_seconds", fmt.Sprintf("operation=%q,", operation))
+	}
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics.write(w, s.kitchen.Snapshot())
+}
+
+// -- Histogram --
+
+type histogram struct {
+	bounds []float64
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]uint64, len(bounds))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// write writes the buckets, sum and count of the histogram. labels is empty or a list of labels
+// ending with a comma.
+func (h *histogram) write(w io.Writer, name string, labels string) {
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{%sle=%q} %d\n", name, labels, formatFloat(bound), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", name, labels, h.count)
+
+	if labels == "" {
+		fmt.Fprintf(w, "%s_sum %s\n", name, formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count %d\n", name, h.count)
+		return
+	}
+	labels = labels[:len(labels)-1]
+	fmt.Fprintf(w, "%s_sum{%s} %s\n", name, labels, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels, h.count)
+}
+
+// -- Helper Functions --
+
+func header(w io.Writer, name string, kind string, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
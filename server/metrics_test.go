@@ -0,0 +1,79 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	css "challenge/client"
+	"challenge/kitchen"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Metrics(t *testing.T) {
+	clock := kitchen.NewFakeClock(time.Unix(1_700_000_000, 0))
+	events, metrics := kitchen.NewBroadcastSink(16), NewMetrics()
+	k := kitchen.NewKitchen(1, 1, 2, 2, kitchen.MultiSink{events, metrics}, clock)
+	ts := httptest.NewServer(NewServer(k, events, metrics))
+	defer ts.Close()
+
+	post := func(path string, body any) {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		resp, err := http.Post(ts.URL+path, "application/json", bytes.NewReader(data))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	post("/orders", css.Order{ID: "hot1", Name: "Hot Pizza", Temp: "hot", Price: 10, Freshness: 60})
+	post("/orders", css.Order{ID: "hot2", Name: "Hot Soup", Temp: "hot", Price: 8, Freshness: 20})
+	clock.Advance(15*time.Second + 500*time.Millisecond)
+	post("/orders/hot1/pickup", nil)
+	post("/orders/hot2/pickup", nil) // expired on the shelf
+	post("/orders/missing/pickup", nil)
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	text := string(body)
+
+	t.Run("Storages", func(t *testing.T) {
+		require.Contains(t, text, "# TYPE kitchen_storage_capacity gauge\n")
+		require.Contains(t, text, `kitchen_storage_capacity{storage="shelf"} 2`+"\n")
+		require.Contains(t, text, `kitchen_storage_occupancy{storage="heater"} 0`+"\n")
+	})
+
+	t.Run("Actions", func(t *testing.T) {
+		require.Contains(t, text, "# TYPE kitchen_actions_total counter\n")
+		require.Contains(t, text, `kitchen_actions_total{action="place"} 2`+"\n")
+		require.Contains(t, text, `kitchen_actions_total{action="move"} 0`+"\n")
+		require.Contains(t, text, `kitchen_actions_total{action="pickup"} 1`+"\n")
+		require.Contains(t, text, `kitchen_actions_total{action="discard"} 1`+"\n")
+	})
+
+	t.Run("Freshness", func(t *testing.T) {
+		require.Contains(t, text, "# TYPE kitchen_pickup_freshness_seconds histogram\n")
+		require.Contains(t, text, `kitchen_pickup_freshness_seconds_bucket{le="0"} 1`+"\n")
+		require.Contains(t, text, `kitchen_pickup_freshness_seconds_bucket{le="30"} 1`+"\n")
+		require.Contains(t, text, `kitchen_pickup_freshness_seconds_bucket{le="60"} 2`+"\n")
+		require.Contains(t, text, `kitchen_pickup_freshness_seconds_bucket{le="+Inf"} 2`+"\n")
+		require.Contains(t, text, "kitchen_pickup_freshness_seconds_sum 33.5\n")
+		require.Contains(t, text, "kitchen_pickup_freshness_seconds_count 2\n")
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		require.Contains(t, text, "# TYPE kitchen_operation_duration_seconds histogram\n")
+		require.Contains(t, text, `kitchen_operation_duration_seconds_bucket{operation="place",le="+Inf"} 2`+"\n")
+		require.Contains(t, text, `kitchen_operation_duration_seconds_count{operation="place"} 2`+"\n")
+		require.Contains(t, text, `kitchen_operation_duration_seconds_count{operation="pickup"} 3`+"\n")
+	})
+}
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	css "challenge/client"
+	"challenge/kitchen"
+)
+
+// Server exposes a kitchen over a JSON HTTP API:
+//
+//	POST /orders              places an order
+//	POST /orders/{id}/pickup  picks up an order
+//	GET  /orders/{id}         returns an order in the kitchen
+//	GET  /orders/{id}/status  returns the state and history of an order
+//	GET  /inventory           returns the content of every storage
+//	GET  /events              streams actions as Server-Sent Events
+//	GET  /events/ws           streams actions over a WebSocket
+//	GET  /metrics             returns metrics in the Prometheus text format
+//
+// Event streams can be filtered with the id and target query parameters.
+type Server struct {
+	kitchen *kitchen.Kitchen
+	events  *kitchen.BroadcastSink
+	metrics *Metrics
+	mux     *http.ServeMux
+}
+
+// NewServer returns a server for the kitchen. Events are streamed from the broadcast sink and
+// actions are counted by the metrics, both must receive the actions of the kitchen.
+func NewServer(k *kitchen.Kitchen, events *kitchen.BroadcastSink, metrics *Metrics) *Server {
+	s := &Server{
+		kitchen: k,
+		events:  events,
+		metrics: metrics,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("POST /orders", s.handlePlace)
+	s.mux.HandleFunc("POST /orders/{id}/pickup", s.handlePickup)
+	s.mux.HandleFunc("GET /orders/{id}", s.handleGet)
+	s.mux.HandleFunc("GET /orders/{id}/status", s.handleStatus)
+	s.mux.HandleFunc("GET /inventory", s.handleInventory)
+	s.mux.HandleFunc("GET /events", s.handleEvents)
+	s.mux.HandleFunc("GET /events/ws", s.handleWebSocket)
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// -- Responses --
+
+// Order is an order stored in the kitchen. Freshness is the remaining freshness, in seconds.
+type Order struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Temp      string    `json:"temp"`
+	Price     int       `json:"price"`
+	Freshness int       `json:"freshness"`
+	Storage   string    `json:"storage"`
+	PlacedAt  time.Time `json:"placedAt"` // when the order was placed in the kitchen
+	StoredAt  time.Time `json:"storedAt"` // when the order was put in its storage
+}
+
+// Storage is the content of a storage.
+type Storage struct {
+	Name      string  `json:"name"`
+	Capacity  int64   `json:"capacity"`
+	Occupancy int64   `json:"occupancy"`
+	Orders    []Order `json:"orders"`
+}
+
+// Inventory is the content of the kitchen at a point in time.
+type Inventory struct {
+	Time     time.Time `json:"time"`
+	Storages []Storage `json:"storages"`
+}
+
+// Status is the state of an order and how it got there. Location and Freshness, in seconds, are
+// only set while the order is stored.
+type Status struct {
+	ID        string       `json:"id"`
+	State     string       `json:"state"`
+	Location  string       `json:"location,omitempty"`
+	Freshness int          `json:"freshness,omitempty"`
+	History   []Transition `json:"history"`
+}
+
+// Transition is a step in the lifecycle of an order.
+type Transition struct {
+	State  string    `json:"state"`
+	Target string    `json:"target"`
+	Time   time.Time `json:"time"`
+}
+
+// Error is the body of every error response. Fields lists the invalid fields of a rejected order.
+type Error struct {
+	Error  string                   `json:"error"`
+	Fields kitchen.ValidationErrors `json:"fields,omitempty"`
+	Order  *css.Order               `json:"order,omitempty"`
+}
+
+// -- Handlers --
+
+func (s *Server) handlePlace(w http.ResponseWriter, r *http.Request) {
+	var order css.Order
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		writeJSON(w, http.StatusBadRequest, Error{Error: fmt.Sprintf("invalid order: %v", err)})
+		return
+	}
+
+	start := time.Now()
+	err := s.kitchen.PlaceOrder(order)
+	s.metrics.observeLatency(css.Place, time.Since(start))
+
+	if err != nil {
+		var invalid kitchen.ValidationErrors
+		switch {
+		case errors.As(err, &invalid):
+			writeJSON(w, http.StatusUnprocessableEntity, Error{Error: err.Error(), Fields: invalid})
+		case errors.Is(err, kitchen.ErrDuplicateOrder):
+			writeJSON(w, http.StatusConflict, Error{Error: err.Error()})
+		case errors.Is(err, kitchen.ErrKitchenFull):
+			writeJSON(w, http.StatusServiceUnavailable, Error{Error: err.Error()})
+		default:
+			writeJSON(w, http.StatusInternalServerError, Error{Error: err.Error()})
+		}
+		return
+	}
+
+	// The order may already be gone if it was discarded or picked up in the meantime
+	if stored, ok := findOrder(s.kitchen.Snapshot(), order.ID); ok {
+		writeJSON(w, http.StatusCreated, stored)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) handlePickup(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	picked, err := s.kitchen.PickUp(r.PathValue("id"))
+	s.metrics.observeLatency(css.Pickup, time.Since(start))
+
+	if err != nil {
+		var expired *kitchen.ExpiredOrderError
+		switch {
+		case errors.As(err, &expired):
+			s.metrics.observeFreshness(expired.Freshness)
+			writeJSON(w, http.StatusGone, Error{Error: err.Error(), Order: &expired.Order})
+		case errors.Is(err, kitchen.ErrOrderNotFound):
+			writeJSON(w, http.StatusNotFound, Error{Error: err.Error()})
+		default:
+			writeJSON(w, http.StatusInternalServerError, Error{Error: err.Error()})
+		}
+		return
+	}
+
+	s.metrics.observeFreshness(picked.Freshness)
+	writeJSON(w, http.StatusOK, picked.Order)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	order, ok := findOrder(s.kitchen.Snapshot(), r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, Error{Error: kitchen.ErrOrderNotFound.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, order)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, ok := s.kitchen.Status(r.PathValue("id"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, Error{Error: kitchen.ErrOrderNotFound.Error()})
+		return
+	}
+
+	resp := Status{
+		ID:        status.ID,
+		State:     string(status.State),
+		Location:  status.Location,
+		Freshness: int(status.Freshness / time.Second),
+		History:   make([]Transition, len(status.History)),
+	}
+	for i, transition := range status.History {
+		resp.History[i] = Transition{
+			State:  string(transition.State),
+			Target: transition.Target,
+			Time:   transition.Time,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleInventory(w http.ResponseWriter, r *http.Request) {
+	snapshot := s.kitchen.Snapshot()
+
+	inventory := Inventory{
+		Time:     snapshot.Time,
+		Storages: make([]Storage, len(snapshot.Storages)),
+	}
+	for i, storage := range snapshot.Storages {
+		orders := make([]Order, len(storage.Orders))
+		for j, stored := range storage.Orders {
+			orders[j] = newOrder(stored, storage.Name)
+		}
+
+		inventory.Storages[i] = Storage{
+			Name:      storage.Name,
+			Capacity:  storage.Capacity,
+			Occupancy: storage.Occupancy,
+			Orders:    orders,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, inventory)
+}
+
+// -- Helper Functions --
+
+func findOrder(snapshot kitchen.Snapshot, id string) (Order, bool) {
+	for _, storage := range snapshot.Storages {
+		for _, stored := range storage.Orders {
+			if stored.ID == id {
+				return newOrder(stored, storage.Name), true
+			}
+		}
+	}
+	return Order{}, false
+}
+
+func newOrder(stored kitchen.StoredOrder, storage string) Order {
+	return Order{
+		ID:        stored.ID,
+		Name:      stored.Name,
+		Temp:      string(stored.Temperature),
+		Price:     stored.Price,
+		Freshness: int(stored.Freshness / time.Second),
+		Storage:   storage,
+		PlacedAt:  stored.PlacedAt,
+		StoredAt:  stored.StoredAt,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	css "challenge/client"
+	"challenge/kitchen"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	hot := css.Order{ID: "hot1", Name: "Hot Pizza", Temp: "hot", Price: 10, Freshness: 60}
+
+	newServer := func(t *testing.T) (*httptest.Server, *kitchen.FakeClock) {
+		clock := kitchen.NewFakeClock(time.Unix(1_700_000_000, 0).UTC())
+		events := kitchen.NewBroadcastSink(16)
+		metrics := NewMetrics()
+		k := kitchen.NewKitchen(1, 1, 2, 2, kitchen.MultiSink{events, metrics}, clock)
+		ts := httptest.NewServer(NewServer(k, events, metrics))
+		t.Cleanup(ts.Close)
+		return ts, clock
+	}
+
+	do := func(t *testing.T, method, url string, body any, out any) int {
+		var buf bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+
+		req, err := http.NewRequest(method, url, &buf)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		if out != nil {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+		}
+		return resp.StatusCode
+	}
+
+	t.Run("PlaceOrder_ReturnsStoredOrder", func(t *testing.T) {
+		ts, _ := newServer(t)
+
+		var order Order
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, ts.URL+"/orders", hot, &order))
+		require.Equal(t, "hot1", order.ID)
+		require.Equal(t, css.Heater, order.Storage)
+		require.Equal(t, 60, order.Freshness)
+		require.Equal(t, time.Unix(1_700_000_000, 0).UTC(), order.PlacedAt)
+		require.Equal(t, order.PlacedAt, order.StoredAt)
+	})
+
+	t.Run("PlaceOrder_Returns422_WhenOrderIsInvalid", func(t *testing.T) {
+		ts, _ := newServer(t)
+
+		var resp Error
+		status := do(t, http.MethodPost, ts.URL+"/orders", css.Order{ID: "bad", Temp: "warm", Price: 1, Freshness: 1}, &resp)
+		require.Equal(t, http.StatusUnprocessableEntity, status)
+		require.Equal(t, kitchen.ValidationErrors{
+			{Field: "Name", Message: "is required"},
+			{Field: "Temp", Message: "must be one of hot, cold, room, or frozen"},
+		}, resp.Fields)
+	})
+
+	t.Run("PlaceOrder_Returns409_WhenOrderIsDuplicate", func(t *testing.T) {
+		ts, _ := newServer(t)
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, ts.URL+"/orders", hot, nil))
+
+		// Retrying the same order is fine
+		var order Order
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, ts.URL+"/orders", hot, &order))
+		require.Equal(t, css.Heater, order.Storage)
+
+		changed := hot
+		changed.Name = "Hot Calzone"
+		var resp Error
+		require.Equal(t, http.StatusConflict, do(t, http.MethodPost, ts.URL+"/orders", changed, &resp))
+		require.Equal(t, "duplicate order hot1", resp.Error)
+	})
+
+	t.Run("PlaceOrder_Returns503_WhenKitchenIsFull", func(t *testing.T) {
+		events := kitchen.NewBroadcastSink(16)
+		k := kitchen.NewKitchen(0, 0, 0, 2, events, kitchen.NewFakeClock(time.Now()))
+		ts := httptest.NewServer(NewServer(k, events, NewMetrics()))
+		defer ts.Close()
+
+		var resp Error
+		require.Equal(t, http.StatusServiceUnavailable, do(t, http.MethodPost, ts.URL+"/orders", hot, &resp))
+		require.Equal(t, "kitchen is full", resp.Error)
+	})
+
+	t.Run("PlaceOrder_Returns400_WhenBodyIsNotJSON", func(t *testing.T) {
+		ts, _ := newServer(t)
+
+		resp, err := http.Post(ts.URL+"/orders", "application/json", bytes.NewBufferString("not json"))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("GetOrder_ReturnsRemainingFreshness", func(t *testing.T) {
+		ts, clock := newServer(t)
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, ts.URL+"/orders", hot, nil))
+		clock.Advance(10 * time.Second)
+
+		var order Order
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, ts.URL+"/orders/hot1", nil, &order))
+		require.Equal(t, 50, order.Freshness)
+
+		require.Equal(t, http.StatusNotFound, do(t, http.MethodGet, ts.URL+"/orders/missing", nil, nil))
+	})
+
+	t.Run("PickUpOrder_RemovesOrder", func(t *testing.T) {
+		ts, _ := newServer(t)
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, ts.URL+"/orders", hot, nil))
+
+		var order css.Order
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, ts.URL+"/orders/hot1/pickup", nil, &order))
+		require.Equal(t, hot, order)
+
+		require.Equal(t, http.StatusNotFound, do(t, http.MethodPost, ts.URL+"/orders/hot1/pickup", nil, nil))
+		require.Equal(t, http.StatusNotFound, do(t, http.MethodGet, ts.URL+"/orders/hot1", nil, nil))
+	})
+
+	t.Run("Status_ReturnsHistory", func(t *testing.T) {
+		ts, _ := newServer(t)
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, ts.URL+"/orders", hot, nil))
+
+		var status Status
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, ts.URL+"/orders/hot1/status", nil, &status))
+		require.Equal(t, "placed", status.State)
+		require.Equal(t, css.Heater, status.Location)
+		require.Equal(t, 60, status.Freshness)
+
+		require.Equal(t, http.StatusOK, do(t, http.MethodPost, ts.URL+"/orders/hot1/pickup", nil, nil))
+		status = Status{}
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, ts.URL+"/orders/hot1/status", nil, &status))
+		require.Equal(t, "picked_up", status.State)
+		require.Empty(t, status.Location)
+		require.Len(t, status.History, 2)
+		require.Equal(t, css.Heater, status.History[1].Target)
+
+		require.Equal(t, http.StatusNotFound, do(t, http.MethodGet, ts.URL+"/orders/missing/status", nil, nil))
+	})
+
+	t.Run("PickUpOrder_Returns410_WhenOrderExpired", func(t *testing.T) {
+		ts, clock := newServer(t)
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, ts.URL+"/orders", hot, nil))
+		clock.Advance(2 * time.Minute)
+
+		var resp Error
+		require.Equal(t, http.StatusGone, do(t, http.MethodPost, ts.URL+"/orders/hot1/pickup", nil, &resp))
+		require.NotNil(t, resp.Order)
+		require.Equal(t, "hot1", resp.Order.ID)
+	})
+
+	t.Run("Inventory_ListsEveryStorage", func(t *testing.T) {
+		ts, _ := newServer(t)
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, ts.URL+"/orders", hot, nil))
+		hot2 := css.Order{ID: "hot2", Name: "Hot Soup", Temp: "hot", Price: 8, Freshness: 70}
+		require.Equal(t, http.StatusCreated, do(t, http.MethodPost, ts.URL+"/orders", hot2, nil))
+
+		var inventory Inventory
+		require.Equal(t, http.StatusOK, do(t, http.MethodGet, ts.URL+"/inventory", nil, &inventory))
+		require.Len(t, inventory.Storages, 3)
+
+		occupancy := map[string]int64{}
+		for _, storage := range inventory.Storages {
+			occupancy[storage.Name] = storage.Occupancy
+			require.Len(t, storage.Orders, int(storage.Occupancy))
+		}
+		require.Equal(t, map[string]int64{css.Heater: 1, css.Cooler: 0, css.Shelf: 1}, occupancy)
+	})
+}
@@ -0,0 +1,137 @@
+package simulation
+
+import (
+	"container/heap"
+	"math/rand/v2"
+	"time"
+
+	css "challenge/client"
+	"challenge/kitchen"
+)
+
+// Config describes the kitchen and the harness parameters of a simulated run.
+type Config struct {
+	FreezerCapacity int64 // zero for a kitchen without a freezer
+	HeaterCapacity  int64
+	CoolerCapacity  int64
+	ShelfCapacity   int64
+	Decay           int
+
+	Rate time.Duration // inverse order rate
+	Min  time.Duration // minimum pickup time
+	Max  time.Duration // maximum pickup time
+
+	ReapInterval time.Duration // how often expired orders are discarded, zero disables the reaper
+
+	Start time.Time  // virtual start time, defaults to the current time
+	Rand  *rand.Rand // source of pickup delays, defaults to a random seed
+
+	Options []kitchen.Option // extra kitchen options, such as the discard policy
+}
+
+type eventKind int
+
+const (
+	placeEvent eventKind = iota
+	pickupEvent
+	reapEvent
+)
+
+type event struct {
+	at    time.Time
+	seq   int
+	kind  eventKind
+	order css.Order
+}
+
+// eventQueue is a min-heap of events ordered by time, then by scheduling order.
+type eventQueue []*event
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x any) { *q = append(*q, x.(*event)) }
+
+func (q *eventQueue) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	*q = old[:n-1]
+	return e
+}
+
+// Run replays the placement and pickup of orders on a virtual clock and returns
+// the resulting ledger. Orders are placed every cfg.Rate and picked up after a
+// random delay between cfg.Min and cfg.Max, exactly like the real-time harness,
+// but without waiting. It returns an error if a capacity is negative.
+func Run(orders []css.Order, cfg Config) ([]css.Action, error) {
+	start := cfg.Start
+	if start.IsZero() {
+		start = time.Now()
+	}
+
+	rnd := cfg.Rand
+	if rnd == nil {
+		rnd = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+
+	clock := kitchen.NewFakeClock(start)
+	sink := kitchen.NewMemorySink()
+	units := kitchen.DefaultStorageUnits(cfg.HeaterCapacity, cfg.CoolerCapacity, cfg.FreezerCapacity, cfg.ShelfCapacity, cfg.Decay)
+	k, err := kitchen.NewKitchenWithUnits(units, sink, clock, cfg.Options...)
+	if err != nil {
+		return nil, err
+	}
+
+	var queue eventQueue
+	seq := 0
+	schedule := func(at time.Time, kind eventKind, order css.Order) {
+		heap.Push(&queue, &event{at: at, seq: seq, kind: kind, order: order})
+		seq++
+	}
+
+	for i, order := range orders {
+		schedule(start.Add(time.Duration(i+1)*cfg.Rate), placeEvent, order)
+	}
+
+	if cfg.ReapInterval > 0 && queue.Len() > 0 {
+		schedule(start.Add(cfg.ReapInterval), reapEvent, css.Order{})
+	}
+
+	for queue.Len() > 0 {
+		e := heap.Pop(&queue).(*event)
+		clock.Set(e.at)
+
+		switch e.kind {
+		case placeEvent:
+			k.PlaceOrder(e.order)
+			schedule(e.at.Add(pickupDelay(rnd, cfg.Min, cfg.Max)), pickupEvent, e.order)
+		case pickupEvent:
+			k.PickUpOrder(e.order.ID)
+		case reapEvent:
+			k.Reap()
+
+			// Keep sweeping for as long as orders are still coming or waiting for pickup
+			if queue.Len() > 0 {
+				schedule(e.at.Add(cfg.ReapInterval), reapEvent, css.Order{})
+			}
+		}
+	}
+
+	return sink.Actions(), nil
+}
+
+func pickupDelay(rnd *rand.Rand, min, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+	return min + time.Duration(rnd.Int64N(int64(max-min)))
+}
@@ -0,0 +1,158 @@
+package simulation
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	css "challenge/client"
+	"challenge/kitchen"
+	"challenge/mockserver"
+	"challenge/validate"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	orders := []css.Order{
+		{ID: "hot1", Name: "Hot Pizza", Temp: "hot", Price: 10, Freshness: 600},
+		{ID: "cold1", Name: "Cold Salad", Temp: "cold", Price: 5, Freshness: 900},
+		{ID: "room1", Name: "Room Sandwich", Temp: "room", Price: 7, Freshness: 180},
+	}
+
+	start := time.Unix(1_700_000_000, 0)
+	cfg := Config{
+		HeaterCapacity: 6,
+		CoolerCapacity: 6,
+		ShelfCapacity:  12,
+		Decay:          2,
+		Rate:           500 * time.Millisecond,
+		Min:            4 * time.Second,
+		Max:            8 * time.Second,
+		Start:          start,
+	}
+
+	at := func(d time.Duration) int64 {
+		return start.Add(d).UnixMicro()
+	}
+
+	t.Run("ProducesLedgerWithVirtualTimestamps", func(t *testing.T) {
+		cfg := cfg
+		cfg.Max = cfg.Min
+
+		actions, err := Run(orders, cfg)
+		require.NoError(t, err)
+
+		expected := []css.Action{
+			{Timestamp: at(500 * time.Millisecond), ID: "hot1", Action: css.Place, Target: css.Heater},
+			{Timestamp: at(1000 * time.Millisecond), ID: "cold1", Action: css.Place, Target: css.Cooler},
+			{Timestamp: at(1500 * time.Millisecond), ID: "room1", Action: css.Place, Target: css.Shelf},
+			{Timestamp: at(4500 * time.Millisecond), ID: "hot1", Action: css.Pickup, Target: css.Heater},
+			{Timestamp: at(5000 * time.Millisecond), ID: "cold1", Action: css.Pickup, Target: css.Cooler},
+			{Timestamp: at(5500 * time.Millisecond), ID: "room1", Action: css.Pickup, Target: css.Shelf},
+		}
+		require.Equal(t, expected, actions)
+	})
+
+	t.Run("PicksUpWithinWindow", func(t *testing.T) {
+		cfg := cfg
+		cfg.Rand = rand.New(rand.NewPCG(1, 2))
+
+		actions, err := Run(orders, cfg)
+		require.NoError(t, err)
+		require.Len(t, actions, 6)
+
+		placed := map[string]int64{}
+		for _, a := range actions {
+			switch a.Action {
+			case css.Place:
+				placed[a.ID] = a.Timestamp
+			case css.Pickup:
+				delay := time.Duration(a.Timestamp-placed[a.ID]) * time.Microsecond
+				require.GreaterOrEqual(t, delay, cfg.Min)
+				require.Less(t, delay, cfg.Max)
+			}
+		}
+	})
+
+	t.Run("DiscardsExpiredOrders_WhenReaperIsEnabled", func(t *testing.T) {
+		cfg := cfg
+		cfg.Max = cfg.Min
+		cfg.ReapInterval = time.Second
+
+		stale := []css.Order{{ID: "hot1", Name: "Hot Pizza", Temp: "hot", Price: 10, Freshness: 2}}
+		actions, err := Run(stale, cfg)
+		require.NoError(t, err)
+
+		expected := []css.Action{
+			{Timestamp: at(500 * time.Millisecond), ID: "hot1", Action: css.Place, Target: css.Heater},
+			{Timestamp: at(3 * time.Second), ID: "hot1", Action: css.Discard, Target: css.Heater},
+		}
+		require.Equal(t, expected, actions)
+	})
+
+	t.Run("IsReproducibleWithSameSeed", func(t *testing.T) {
+		first := cfg
+		first.Rand = rand.New(rand.NewPCG(7, 7))
+		second := cfg
+		second.Rand = rand.New(rand.NewPCG(7, 7))
+
+		firstActions, err := Run(orders, first)
+		require.NoError(t, err)
+		secondActions, err := Run(orders, second)
+		require.NoError(t, err)
+		require.Equal(t, firstActions, secondActions)
+	})
+}
+
+func TestRun_LedgersPassValidation(t *testing.T) {
+	// Places and reaps land on the same microsecond every second
+	cfg := Config{
+		HeaterCapacity: 6,
+		CoolerCapacity: 6,
+		ShelfCapacity:  12,
+		Decay:          2,
+		Rate:           time.Second,
+		Min:            20 * time.Second,
+		Max:            40 * time.Second,
+		ReapInterval:   time.Second,
+		Start:          time.Unix(1_700_000_000, 0),
+	}
+	options := css.Options{Rate: cfg.Rate.Microseconds(), Min: cfg.Min.Microseconds(), Max: cfg.Max.Microseconds()}
+
+	for _, placement := range []string{kitchen.PlacementDefault, kitchen.PlacementFreshness, kitchen.PlacementNoMove} {
+		for _, freezer := range []int64{0, 3} {
+			t.Run(fmt.Sprintf("%s_Freezer%d", placement, freezer), func(t *testing.T) {
+				strategy, err := kitchen.PlacementStrategyByName(placement)
+				require.NoError(t, err)
+
+				for seed := range int64(30) {
+					cfg := cfg
+					cfg.FreezerCapacity = freezer
+					cfg.Rand = rand.New(rand.NewPCG(uint64(seed), 0))
+					cfg.Options = []kitchen.Option{kitchen.WithPlacementStrategy(strategy)}
+
+					// The challenge server has no frozen orders, make every fifth one frozen
+					orders := mockserver.GenerateOrders(seed, 60)
+					for i := range orders {
+						if i%5 == 0 {
+							orders[i].Temp = "frozen"
+						}
+					}
+
+					actions, err := Run(orders, cfg)
+					require.NoError(t, err)
+					report := validate.Validate(orders, options, actions, validate.Config{
+						FreezerCapacity: cfg.FreezerCapacity,
+						HeaterCapacity:  cfg.HeaterCapacity,
+						CoolerCapacity:  cfg.CoolerCapacity,
+						ShelfCapacity:   cfg.ShelfCapacity,
+						Decay:           cfg.Decay,
+					})
+					require.True(t, report.Passed(), "seed %d: %v", seed, report)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	css "challenge/client"
+)
+
+// Rule identifies the check a violation failed.
+type Rule string
+
+const (
+	RuleUnknownOrder   Rule = "unknown-order"
+	RuleInvalidAction  Rule = "invalid-action"
+	RuleInvalidTarget  Rule = "invalid-target"
+	RuleOrdering       Rule = "ordering"
+	RuleLifecycle      Rule = "lifecycle"
+	RuleCapacity       Rule = "capacity"
+	RulePlacement      Rule = "placement"
+	RuleMove           Rule = "move"
+	RuleDiscard        Rule = "discard"
+	RuleDiscardOrder   Rule = "discard-order"
+	RulePickupWindow   Rule = "pickup-window"
+	RuleFreshness      Rule = "freshness"
+	RuleIncompleteWork Rule = "incomplete"
+)
+
+// Config describes the kitchen the ledger was produced by, made of the default storage units of
+// kitchen.DefaultStorageUnits.
+type Config struct {
+	FreezerCapacity int64 // zero for a kitchen without a freezer
+	HeaterCapacity  int64
+	CoolerCapacity  int64
+	ShelfCapacity   int64
+	Decay           int
+
+	// Tolerance is the slack allowed on each side of the pickup window.
+	Tolerance time.Duration
+	// IgnoreDiscardOrder skips checking that overflow discards follow the README discard criteria.
+	IgnoreDiscardOrder bool
+}
+
+// Violation is a single broken rule. Index is the position of the offending action in the
+// ledger, or -1 when the violation is about an order as a whole.
+type Violation struct {
+	Index   int    `json:"index"`
+	ID      string `json:"id"`
+	Action  string `json:"action,omitempty"`
+	Rule    Rule   `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Index < 0 {
+		return fmt.Sprintf("order %s: %s: %s", v.ID, v.Rule, v.Message)
+	}
+	return fmt.Sprintf("action %d (%s %s): %s: %s", v.Index, v.Action, v.ID, v.Rule, v.Message)
+}
+
+// Report is the outcome of validating a ledger.
+type Report struct {
+	Orders     int         `json:"orders"`
+	Actions    int         `json:"actions"`
+	Violations []Violation `json:"violations"`
+}
+
+func (r Report) Passed() bool {
+	return len(r.Violations) == 0
+}
+
+func (r Report) String() string {
+	if r.Passed() {
+		return fmt.Sprintf("pass: %d orders, %d actions", r.Orders, r.Actions)
+	}
+
+	lines := make([]string, 0, len(r.Violations)+1)
+	lines = append(lines, fmt.Sprintf("fail: %d violations", len(r.Violations)))
+	for _, v := range r.Violations {
+		lines = append(lines, "  "+v.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// orderState tracks an order while the ledger is replayed. Times are unix microseconds and
+// freshness is in microseconds.
+type orderState struct {
+	order     css.Order
+	placed    bool
+	done      bool
+	placedAt  int64
+	location  string
+	enteredAt int64
+	consumed  float64
+}
+
+type validator struct {
+	cfg        Config
+	opts       css.Options
+	orders     map[string]*orderState
+	capacities map[string]int64
+	contents   map[string][]*orderState // storage contents in order of arrival
+	report     Report
+}
+
+// Validate replays actions against the original orders and harness options and reports every
+// rule the ledger breaks.
+func Validate(orders []css.Order, opts css.Options, actions []css.Action, cfg Config) Report {
+	v := &validator{
+		cfg:    cfg,
+		opts:   opts,
+		orders: make(map[string]*orderState, len(orders)),
+		capacities: map[string]int64{
+			css.Heater: cfg.HeaterCapacity,
+			css.Cooler: cfg.CoolerCapacity,
+			css.Shelf:  cfg.ShelfCapacity,
+		},
+		contents: make(map[string][]*orderState, 4),
+		report:   Report{Orders: len(orders), Actions: len(actions)},
+	}
+	if cfg.FreezerCapacity > 0 {
+		v.capacities[css.Freezer] = cfg.FreezerCapacity
+	}
+
+	for _, o := range orders {
+		v.orders[o.ID] = &orderState{order: o}
+	}
+
+	var last int64
+	for i, a := range actions {
+		if i > 0 && a.Timestamp < last {
+			v.fail(i, a, RuleOrdering, "timestamp %d is before previous action at %d", a.Timestamp, last)
+		}
+		last = a.Timestamp
+
+		v.apply(i, a)
+	}
+
+	for _, o := range orders {
+		s := v.orders[o.ID]
+		switch {
+		case !s.placed:
+			v.failOrder(o.ID, RuleIncompleteWork, "never placed")
+		case !s.done:
+			v.failOrder(o.ID, RuleIncompleteWork, "never picked up or discarded")
+		}
+	}
+
+	return v.report
+}
+
+func (v *validator) apply(i int, a css.Action) {
+	s, ok := v.orders[a.ID]
+	if !ok {
+		v.fail(i, a, RuleUnknownOrder, "order is not part of the problem")
+		return
+	}
+
+	if _, ok := v.capacities[a.Target]; !ok {
+		v.fail(i, a, RuleInvalidTarget, "target must be one of %s", v.storages())
+		return
+	}
+
+	switch a.Action {
+	case css.Place:
+		v.place(i, a, s)
+	case css.Move:
+		v.move(i, a, s)
+	case css.Pickup:
+		v.pickup(i, a, s)
+	case css.Discard:
+		v.discard(i, a, s)
+	default:
+		v.fail(i, a, RuleInvalidAction, "action must be one of place, move, pickup or discard")
+	}
+}
+
+func (v *validator) place(i int, a css.Action, s *orderState) {
+	if s.placed {
+		v.fail(i, a, RuleLifecycle, "order was already placed")
+		return
+	}
+
+	if a.Target != css.Shelf && !isIdealStorage(s.order.Temp, a.Target) {
+		v.fail(i, a, RulePlacement, "%s order cannot be placed on the %s", s.order.Temp, a.Target)
+	}
+
+	s.placed = true
+	s.placedAt = a.Timestamp
+	v.enter(i, a, s)
+}
+
+func (v *validator) move(i int, a css.Action, s *orderState) {
+	if !v.inKitchen(i, a, s) {
+		return
+	}
+
+	if s.location != css.Shelf || !isIdealStorage(s.order.Temp, a.Target) {
+		v.fail(i, a, RuleMove, "%s order cannot move from the %s to the %s", s.order.Temp, s.location, a.Target)
+	}
+
+	v.leave(s, a.Timestamp)
+	v.enter(i, a, s)
+}
+
+func (v *validator) pickup(i int, a css.Action, s *orderState) {
+	if !v.inKitchen(i, a, s) {
+		return
+	}
+
+	elapsed := time.Duration(a.Timestamp-s.placedAt) * time.Microsecond
+	min := time.Duration(v.opts.Min)*time.Microsecond - v.cfg.Tolerance
+	max := time.Duration(v.opts.Max)*time.Microsecond + v.cfg.Tolerance
+	if elapsed < min || elapsed > max {
+		v.fail(i, a, RulePickupWindow, "picked up %v after placement, outside [%v, %v]", elapsed, min, max)
+	}
+
+	if remaining := v.remaining(s, a.Timestamp); remaining <= 0 {
+		v.fail(i, a, RuleFreshness, "picked up %v past its freshness", time.Duration(-remaining)*time.Microsecond)
+	}
+
+	v.leave(s, a.Timestamp)
+	s.done = true
+}
+
+func (v *validator) discard(i int, a css.Action, s *orderState) {
+	if !v.inKitchen(i, a, s) {
+		return
+	}
+
+	expired := v.remaining(s, a.Timestamp) <= 0
+	if !expired {
+		switch {
+		case s.location != css.Shelf:
+			v.fail(i, a, RuleDiscard, "fresh order discarded from the %s", s.location)
+		case int64(len(v.contents[css.Shelf])) < v.capacities[css.Shelf]:
+			v.fail(i, a, RuleDiscard, "fresh order discarded while the shelf had space")
+		case !v.cfg.IgnoreDiscardOrder:
+			if expected := v.discardCandidate(); expected != nil && expected != s {
+				v.fail(i, a, RuleDiscardOrder, "expected order %s to be discarded first", expected.order.ID)
+			}
+		}
+	}
+
+	v.leave(s, a.Timestamp)
+	s.done = true
+}
+
+// inKitchen reports whether the order currently sits in the target storage of the action.
+func (v *validator) inKitchen(i int, a css.Action, s *orderState) bool {
+	if !s.placed || s.done {
+		v.fail(i, a, RuleLifecycle, "order is not in the kitchen")
+		return false
+	}
+
+	if a.Action != css.Move && s.location != a.Target {
+		v.fail(i, a, RuleLifecycle, "order is on the %s, not the %s", s.location, a.Target)
+	}
+	return true
+}
+
+func (v *validator) enter(i int, a css.Action, s *orderState) {
+	s.location = a.Target
+	s.enteredAt = a.Timestamp
+	v.contents[a.Target] = append(v.contents[a.Target], s)
+
+	if n := int64(len(v.contents[a.Target])); n > v.capacities[a.Target] {
+		v.fail(i, a, RuleCapacity, "%s holds %d orders, capacity is %d", a.Target, n, v.capacities[a.Target])
+	}
+}
+
+func (v *validator) leave(s *orderState, at int64) {
+	s.consumed += float64(at-s.enteredAt) * v.decayRate(s)
+
+	items := v.contents[s.location]
+	for j, other := range items {
+		if other == s {
+			v.contents[s.location] = append(items[:j:j], items[j+1:]...)
+			break
+		}
+	}
+}
+
+// remaining returns the freshness left at the given time, in microseconds.
+func (v *validator) remaining(s *orderState, at int64) float64 {
+	budget := float64(s.order.Freshness) * float64(time.Second/time.Microsecond)
+	return budget - s.consumed - float64(at-s.enteredAt)*v.decayRate(s)
+}
+
+// decayRate returns how many times faster than normal an order decays where it is. Frozen orders
+// decay decay times faster in the cooler and decay² times faster on the shelf, other orders decay
+// times faster outside their ideal storage.
+func (v *validator) decayRate(s *orderState) float64 {
+	decay := float64(v.cfg.Decay)
+	switch {
+	case s.order.Temp == "frozen" && s.location == css.Cooler:
+		return decay
+	case s.order.Temp == "frozen" && s.location == css.Shelf:
+		return decay * decay
+	case isIdealStorage(s.order.Temp, s.location):
+		return 1
+	}
+	return decay
+}
+
+// discardCandidate returns the shelf order the README discard criteria select: the oldest of
+// the oldest cold, frozen and hot orders, first in that order on a tie, or else the oldest room
+// order.
+func (v *validator) discardCandidate() *orderState {
+	var cold, frozen, hot, room *orderState
+	for _, s := range v.contents[css.Shelf] {
+		switch {
+		case s.order.Temp == "cold" && cold == nil:
+			cold = s
+		case s.order.Temp == "frozen" && frozen == nil:
+			frozen = s
+		case s.order.Temp == "hot" && hot == nil:
+			hot = s
+		case s.order.Temp == "room" && room == nil:
+			room = s
+		}
+	}
+
+	oldest := room
+	for _, s := range []*orderState{cold, frozen, hot} {
+		if s != nil && (oldest == room || s.enteredAt < oldest.enteredAt) {
+			oldest = s
+		}
+	}
+	return oldest
+}
+
+// storages lists the storages of the kitchen, for error messages.
+func (v *validator) storages() string {
+	if _, ok := v.capacities[css.Freezer]; ok {
+		return "freezer, heater, cooler or shelf"
+	}
+	return "heater, cooler or shelf"
+}
+
+func (v *validator) fail(i int, a css.Action, rule Rule, format string, args ...any) {
+	v.report.Violations = append(v.report.Violations, Violation{
+		Index:   i,
+		ID:      a.ID,
+		Action:  a.Action,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) failOrder(id string, rule Rule, format string, args ...any) {
+	v.report.Violations = append(v.report.Violations, Violation{
+		Index:   -1,
+		ID:      id,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// idealStorages returns the storages an order goes to before the shelf, in order of preference.
+func idealStorages(temp string) []string {
+	switch temp {
+	case "hot":
+		return []string{css.Heater}
+	case "cold":
+		return []string{css.Cooler}
+	case "frozen":
+		return []string{css.Freezer, css.Cooler}
+	default:
+		return []string{css.Shelf}
+	}
+}
+
+func isIdealStorage(temp, storage string) bool {
+	return slices.Contains(idealStorages(temp), storage)
+}
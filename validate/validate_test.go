@@ -0,0 +1,225 @@
+package validate
+
+import (
+	"testing"
+	"time"
+
+	css "challenge/client"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	orders := []css.Order{
+		{ID: "hot1", Name: "Hot Pizza", Temp: "hot", Price: 10, Freshness: 60},
+		{ID: "cold1", Name: "Cold Salad", Temp: "cold", Price: 5, Freshness: 60},
+		{ID: "room1", Name: "Room Sandwich", Temp: "room", Price: 7, Freshness: 60},
+		{ID: "cold2", Name: "Cold Salad", Temp: "cold", Price: 5, Freshness: 60},
+	}
+
+	opts := css.Options{
+		Rate: (500 * time.Millisecond).Microseconds(),
+		Min:  (4 * time.Second).Microseconds(),
+		Max:  (8 * time.Second).Microseconds(),
+	}
+
+	cfg := Config{HeaterCapacity: 1, CoolerCapacity: 1, ShelfCapacity: 1, Decay: 2}
+
+	sec := func(s float64) int64 {
+		return int64(s * float64(time.Second/time.Microsecond))
+	}
+
+	rules := func(r Report) []Rule {
+		var out []Rule
+		for _, v := range r.Violations {
+			out = append(out, v.Rule)
+		}
+		return out
+	}
+
+	t.Run("Passes_WhenLedgerIsValid", func(t *testing.T) {
+		actions := []css.Action{
+			{Timestamp: sec(0.5), ID: "hot1", Action: css.Place, Target: css.Heater},
+			{Timestamp: sec(1.0), ID: "cold1", Action: css.Place, Target: css.Cooler},
+			{Timestamp: sec(1.5), ID: "room1", Action: css.Place, Target: css.Shelf},
+			{Timestamp: sec(2.0), ID: "room1", Action: css.Discard, Target: css.Shelf},
+			{Timestamp: sec(2.0), ID: "cold2", Action: css.Place, Target: css.Shelf},
+			{Timestamp: sec(5.0), ID: "hot1", Action: css.Pickup, Target: css.Heater},
+			{Timestamp: sec(6.0), ID: "cold1", Action: css.Pickup, Target: css.Cooler},
+			{Timestamp: sec(6.5), ID: "cold2", Action: css.Move, Target: css.Cooler},
+			{Timestamp: sec(7.0), ID: "cold2", Action: css.Pickup, Target: css.Cooler},
+		}
+
+		report := Validate(orders, opts, actions, cfg)
+		require.True(t, report.Passed(), report.String())
+	})
+
+	t.Run("Fails_WhenOrdersAreNotCompleted", func(t *testing.T) {
+		actions := []css.Action{
+			{Timestamp: sec(0.5), ID: "hot1", Action: css.Place, Target: css.Heater},
+		}
+
+		report := Validate(orders[:2], opts, actions, cfg)
+		require.Equal(t, []Rule{RuleIncompleteWork, RuleIncompleteWork}, rules(report))
+		require.Equal(t, "never picked up or discarded", report.Violations[0].Message)
+		require.Equal(t, "never placed", report.Violations[1].Message)
+	})
+
+	t.Run("Fails_WhenCapacityIsExceeded", func(t *testing.T) {
+		actions := []css.Action{
+			{Timestamp: sec(0.5), ID: "cold1", Action: css.Place, Target: css.Cooler},
+			{Timestamp: sec(1.0), ID: "cold2", Action: css.Place, Target: css.Cooler},
+			{Timestamp: sec(5.0), ID: "cold1", Action: css.Pickup, Target: css.Cooler},
+			{Timestamp: sec(5.5), ID: "cold2", Action: css.Pickup, Target: css.Cooler},
+		}
+
+		report := Validate([]css.Order{orders[1], orders[3]}, opts, actions, cfg)
+		require.Equal(t, []Rule{RuleCapacity}, rules(report))
+	})
+
+	t.Run("Fails_WhenPlacementOrMoveTargetIsIllegal", func(t *testing.T) {
+		actions := []css.Action{
+			{Timestamp: sec(0.5), ID: "hot1", Action: css.Place, Target: css.Cooler},
+			{Timestamp: sec(1.0), ID: "room1", Action: css.Place, Target: css.Shelf},
+			{Timestamp: sec(1.5), ID: "room1", Action: css.Move, Target: css.Heater},
+			{Timestamp: sec(5.0), ID: "hot1", Action: css.Pickup, Target: css.Cooler},
+			{Timestamp: sec(5.5), ID: "room1", Action: css.Pickup, Target: css.Heater},
+		}
+
+		report := Validate([]css.Order{orders[0], orders[2]}, opts, actions, cfg)
+		require.Equal(t, []Rule{RulePlacement, RuleMove}, rules(report))
+	})
+
+	t.Run("Fails_WhenFreshOrderIsDiscardedWithSpace", func(t *testing.T) {
+		actions := []css.Action{
+			{Timestamp: sec(0.5), ID: "room1", Action: css.Place, Target: css.Shelf},
+			{Timestamp: sec(1.0), ID: "room1", Action: css.Discard, Target: css.Shelf},
+		}
+
+		report := Validate(orders[2:3], opts, actions, Config{ShelfCapacity: 2, Decay: 2})
+		require.Equal(t, []Rule{RuleDiscard}, rules(report))
+	})
+
+	t.Run("Fails_WhenDiscardDoesNotFollowCriteria", func(t *testing.T) {
+		shelf := Config{ShelfCapacity: 2, Decay: 2}
+		actions := []css.Action{
+			{Timestamp: sec(0.5), ID: "room1", Action: css.Place, Target: css.Shelf},
+			{Timestamp: sec(1.0), ID: "cold1", Action: css.Place, Target: css.Shelf},
+			{Timestamp: sec(1.5), ID: "room1", Action: css.Discard, Target: css.Shelf},
+			{Timestamp: sec(1.5), ID: "hot1", Action: css.Place, Target: css.Shelf},
+			{Timestamp: sec(5.0), ID: "cold1", Action: css.Pickup, Target: css.Shelf},
+			{Timestamp: sec(5.5), ID: "hot1", Action: css.Pickup, Target: css.Shelf},
+		}
+
+		report := Validate(orders[:3], opts, actions, shelf)
+		require.Equal(t, []Rule{RuleDiscardOrder}, rules(report))
+		require.Contains(t, report.Violations[0].Message, "cold1")
+
+		shelf.IgnoreDiscardOrder = true
+		require.True(t, Validate(orders[:3], opts, actions, shelf).Passed())
+	})
+
+	t.Run("Fails_WhenFrozenOrderIsNotDiscardedFirst", func(t *testing.T) {
+		frozen := css.Order{ID: "frozen1", Name: "Ice Cream", Temp: "frozen", Price: 6, Freshness: 60}
+		actions := []css.Action{
+			{Timestamp: sec(0.5), ID: "frozen1", Action: css.Place, Target: css.Shelf},
+			{Timestamp: sec(1.0), ID: "cold1", Action: css.Place, Target: css.Shelf},
+			{Timestamp: sec(1.5), ID: "cold1", Action: css.Discard, Target: css.Shelf},
+			{Timestamp: sec(1.5), ID: "hot1", Action: css.Place, Target: css.Shelf},
+			{Timestamp: sec(5.0), ID: "frozen1", Action: css.Pickup, Target: css.Shelf},
+			{Timestamp: sec(5.5), ID: "hot1", Action: css.Pickup, Target: css.Shelf},
+		}
+
+		report := Validate([]css.Order{orders[0], orders[1], frozen}, opts, actions, Config{ShelfCapacity: 2, Decay: 2})
+		require.Equal(t, []Rule{RuleDiscardOrder}, rules(report))
+		require.Contains(t, report.Violations[0].Message, "frozen1")
+	})
+
+	t.Run("FrozenOrders_DecayFasterAwayFromFreezer", func(t *testing.T) {
+		frozen := func(id string) css.Order {
+			return css.Order{ID: id, Name: "Ice Cream", Temp: "frozen", Price: 6, Freshness: 10}
+		}
+		actions := []css.Action{
+			{Timestamp: sec(0), ID: "frozen1", Action: css.Place, Target: css.Freezer},
+			{Timestamp: sec(0), ID: "frozen2", Action: css.Place, Target: css.Cooler},
+			{Timestamp: sec(0), ID: "frozen3", Action: css.Place, Target: css.Shelf},
+			{Timestamp: sec(0), ID: "frozen4", Action: css.Place, Target: css.Shelf},
+			// 1s on the shelf at 4x, then 1s in the cooler at 2x
+			{Timestamp: sec(1), ID: "frozen2", Action: css.Pickup, Target: css.Cooler},
+			{Timestamp: sec(1), ID: "frozen3", Action: css.Move, Target: css.Cooler},
+			// 4.5s takes 4.5s of freshness in the freezer and 18s on the shelf
+			{Timestamp: sec(2), ID: "frozen3", Action: css.Pickup, Target: css.Cooler},
+			{Timestamp: sec(4.5), ID: "frozen1", Action: css.Pickup, Target: css.Freezer},
+			{Timestamp: sec(4.5), ID: "frozen4", Action: css.Pickup, Target: css.Shelf},
+		}
+		frozenOrders := []css.Order{frozen("frozen1"), frozen("frozen2"), frozen("frozen3"), frozen("frozen4")}
+		opts := css.Options{Min: sec(0), Max: sec(8)}
+
+		report := Validate(frozenOrders, opts, actions, Config{FreezerCapacity: 1, CoolerCapacity: 1, ShelfCapacity: 2, Decay: 2})
+		require.Equal(t, []Rule{RuleFreshness}, rules(report))
+		require.Equal(t, "frozen4", report.Violations[0].ID)
+
+		// Without a freezer, there is no freezer to place orders in
+		report = Validate(frozenOrders, opts, actions, Config{CoolerCapacity: 1, ShelfCapacity: 2, Decay: 2})
+		require.Contains(t, rules(report), RuleInvalidTarget)
+	})
+
+	t.Run("Fails_WhenPickupIsOutsideWindow", func(t *testing.T) {
+		actions := []css.Action{
+			{Timestamp: sec(0.5), ID: "hot1", Action: css.Place, Target: css.Heater},
+			{Timestamp: sec(2.0), ID: "hot1", Action: css.Pickup, Target: css.Heater},
+		}
+
+		report := Validate(orders[:1], opts, actions, cfg)
+		require.Equal(t, []Rule{RulePickupWindow}, rules(report))
+
+		cfg := cfg
+		cfg.Tolerance = 3 * time.Second
+		require.True(t, Validate(orders[:1], opts, actions, cfg).Passed())
+	})
+
+	t.Run("Fails_WhenPickedUpOrderIsExpired", func(t *testing.T) {
+		stale := []css.Order{{ID: "cold1", Name: "Cold Salad", Temp: "cold", Price: 5, Freshness: 10}}
+		actions := []css.Action{
+			{Timestamp: sec(0), ID: "cold1", Action: css.Place, Target: css.Shelf},
+			// 3s on the shelf at twice the rate, then 5s in the cooler: 11s of the 10s budget.
+			{Timestamp: sec(3), ID: "cold1", Action: css.Move, Target: css.Cooler},
+			{Timestamp: sec(8), ID: "cold1", Action: css.Pickup, Target: css.Cooler},
+		}
+
+		report := Validate(stale, opts, actions, cfg)
+		require.Equal(t, []Rule{RuleFreshness}, rules(report))
+		require.Equal(t, "picked up 1s past its freshness", report.Violations[0].Message)
+	})
+
+	t.Run("Passes_WhenExpiredOrderIsDiscarded", func(t *testing.T) {
+		stale := []css.Order{{ID: "hot1", Name: "Hot Pizza", Temp: "hot", Price: 5, Freshness: 1}}
+		actions := []css.Action{
+			{Timestamp: sec(0), ID: "hot1", Action: css.Place, Target: css.Heater},
+			{Timestamp: sec(5), ID: "hot1", Action: css.Discard, Target: css.Heater},
+		}
+
+		require.True(t, Validate(stale, opts, actions, cfg).Passed())
+	})
+
+	t.Run("Fails_WhenLedgerIsMalformed", func(t *testing.T) {
+		actions := []css.Action{
+			{Timestamp: sec(1), ID: "hot1", Action: css.Place, Target: css.Heater},
+			{Timestamp: sec(0), ID: "ghost", Action: css.Place, Target: css.Heater},
+			{Timestamp: sec(2), ID: "hot1", Action: "cook", Target: css.Heater},
+			{Timestamp: sec(3), ID: "hot1", Action: css.Pickup, Target: "oven"},
+			{Timestamp: sec(5), ID: "hot1", Action: css.Pickup, Target: css.Shelf},
+			{Timestamp: sec(6), ID: "hot1", Action: css.Pickup, Target: css.Heater},
+		}
+
+		report := Validate(orders[:1], opts, actions, cfg)
+		require.Equal(t, []Rule{
+			RuleOrdering,
+			RuleUnknownOrder,
+			RuleInvalidAction,
+			RuleInvalidTarget,
+			RuleLifecycle,
+			RuleLifecycle,
+		}, rules(report))
+	})
+}